@@ -1,9 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,20 +18,59 @@ const (
 
 // A Logger is an environment values holder for logging
 type Logger struct {
+	Level            string   `yaml:"level"`
+	OutputWriter     string   `yaml:"outputWriter"`
+	OutputDir        string   `yaml:"outputDir"`
+	MaxSizeMB        int      `yaml:"maxSizeMB"`
+	RotationInterval string   `yaml:"rotationInterval"`
+	Compress         bool     `yaml:"compress"`
+	MaxBackups       int      `yaml:"maxBackups"`
+	MaxAgeDays       int      `yaml:"maxAgeDays"`
+	Outputs          []Output `yaml:"outputs"`
+}
+
+// A Output is an environment values holder for one of the logging outputs, used when the logs are sent to multiple outputs
+type Output struct {
 	Level        string `yaml:"level"`
 	OutputWriter string `yaml:"outputWriter"`
-	OutputDir    string `yaml:"outputDir"`
+}
+
+// A TransactionLogger is an environment values holder for transaction logging.
+// The unset values fall back to the Logger ones, see TransactionLoggerConfig.
+// The transaction policies (e.g. StopTimeout) only exist in the TransactionLogger and do not fall back.
+type TransactionLogger struct {
+	Level            string `yaml:"level"`
+	OutputWriter     string `yaml:"outputWriter"`
+	OutputDir        string `yaml:"outputDir"`
+	FileSuffix       string `yaml:"fileSuffix"` // does not fall back, so that the transactions are not written to the files of the logger
+	MaxSizeMB        *int   `yaml:"maxSizeMB"`
+	RotationInterval string `yaml:"rotationInterval"`
+	Compress         *bool  `yaml:"compress"`
+	MaxBackups       *int   `yaml:"maxBackups"`
+	MaxAgeDays       *int   `yaml:"maxAgeDays"`
+
+	StopTimeout    string `yaml:"stopTimeout"`
+	MaxLifetime    string `yaml:"maxLifetime"`
+	FlushLevel     string `yaml:"flushLevel"`
+	MaxEntries     int    `yaml:"maxEntries"`
+	MaxBytes       int    `yaml:"maxBytes"`
+	OverflowPolicy string `yaml:"overflowPolicy"`
 }
 
 // A Config is a generic environment values holder
 type Config struct {
-	Logger Logger `yaml:"logger"`
+	Logger            Logger            `yaml:"logger"`
+	TransactionLogger TransactionLogger `yaml:"transactionLogger"`
 }
 
 var configOnce sync.Once
 var LoggerConfig *Config
 
-// Init uses singleton pattern in order to load the configuration from a YAML file
+var warningsMutex sync.Mutex
+var pendingWarnings []string
+
+// Init uses singleton pattern in order to load the configuration from a YAML file.
+// The warnings of the loading are kept until PrintWarnings is called, so that they can be redirected to a logger first.
 func Init() *Config {
 	configOnce.Do(func() {
 		LoggerConfig = loadConfig()
@@ -49,7 +90,7 @@ func loadConfig() *Config {
 
 	f, err := os.Open(configFileName)
 	if err != nil {
-		log.Println("warning: the logger config file could not be opened. Check if the file exists or if it is corrupt", err)
+		warn("warning: the logger config file could not be opened. Check if the file exists or if it is corrupt", err)
 		return nil
 	}
 	defer f.Close()
@@ -58,8 +99,85 @@ func loadConfig() *Config {
 	decoder := yaml.NewDecoder(f)
 	err = decoder.Decode(&cfg)
 	if err != nil {
-		log.Println("warning: the logger config could not be decoded. Check if the file exists or if it is corrupt", err)
+		warn("warning: the logger config could not be decoded. Check if the file exists or if it is corrupt", err)
 		return nil
 	}
+	validateConfig(&cfg)
 	return &cfg
 }
+
+// validateConfig warns about the values of the configuration that could not be parsed, and unsets them so that their default value is used.
+// The values are checked once, when the configuration is loaded, so that the warnings are not repeated by every logger.
+func validateConfig(cfg *Config) {
+	cfg.Logger.RotationInterval = validDuration(cfg.Logger.RotationInterval, "logger.rotationInterval")
+	cfg.TransactionLogger.RotationInterval = validDuration(cfg.TransactionLogger.RotationInterval, "transactionLogger.rotationInterval")
+	cfg.TransactionLogger.StopTimeout = validDuration(cfg.TransactionLogger.StopTimeout, "transactionLogger.stopTimeout")
+	cfg.TransactionLogger.MaxLifetime = validDuration(cfg.TransactionLogger.MaxLifetime, "transactionLogger.maxLifetime")
+}
+
+// validDuration returns the duration value, or an empty value after a warning if the value could not be parsed.
+func validDuration(value string, key string) string {
+	if value == "" {
+		return value
+	}
+	_, err := time.ParseDuration(value)
+	if err != nil {
+		warn(fmt.Sprintf("warning: the %s duration could not be parsed, the default value is used", key), err)
+		return ""
+	}
+	return value
+}
+
+// PrintWarnings prints the warnings of the configuration loading using the standard log package.
+// Every warning is printed once.
+func PrintWarnings() {
+	warningsMutex.Lock()
+	warnings := pendingWarnings
+	pendingWarnings = nil
+	warningsMutex.Unlock()
+
+	for _, warning := range warnings {
+		log.Print(warning)
+	}
+}
+
+// warn keeps a warning of the configuration loading until PrintWarnings is called, formatted as log.Println does.
+func warn(v ...any) {
+	warningsMutex.Lock()
+	defer warningsMutex.Unlock()
+	pendingWarnings = append(pendingWarnings, fmt.Sprintln(v...))
+}
+
+// TransactionLoggerConfig returns the transaction logging configuration, where the unset values are taken from the Logger configuration.
+// The pointer values are always set in the returned configuration.
+func (c *Config) TransactionLoggerConfig() TransactionLogger {
+	transactionLogger := c.TransactionLogger
+	logger := c.Logger
+
+	transactionLogger.Level = stringOrDefault(transactionLogger.Level, logger.Level)
+	transactionLogger.OutputWriter = stringOrDefault(transactionLogger.OutputWriter, logger.OutputWriter)
+	transactionLogger.OutputDir = stringOrDefault(transactionLogger.OutputDir, logger.OutputDir)
+	transactionLogger.MaxSizeMB = valueOrDefault(transactionLogger.MaxSizeMB, logger.MaxSizeMB)
+	transactionLogger.RotationInterval = stringOrDefault(transactionLogger.RotationInterval, logger.RotationInterval)
+	transactionLogger.Compress = valueOrDefault(transactionLogger.Compress, logger.Compress)
+	transactionLogger.MaxBackups = valueOrDefault(transactionLogger.MaxBackups, logger.MaxBackups)
+	transactionLogger.MaxAgeDays = valueOrDefault(transactionLogger.MaxAgeDays, logger.MaxAgeDays)
+	return transactionLogger
+}
+
+// stringOrDefault returns the value, or the default value if the value is unset
+func stringOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// valueOrDefault returns the value, or a pointer to the default value if the value is unset.
+// Unlike stringOrDefault, a zero value that is set explicitly (e.g. maxBackups: 0) is kept.
+func valueOrDefault[T any](value *T, defaultValue T) *T {
+	if value == nil {
+		return &defaultValue
+	}
+	return value
+}
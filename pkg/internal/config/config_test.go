@@ -1,9 +1,12 @@
 package config
 
 import (
+	"bytes"
+	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 
@@ -64,6 +67,29 @@ func TestInitWithNoConfigSet(t *testing.T) {
 
 }
 
+func TestPrintWarnings(t *testing.T) {
+	configOnce = sync.Once{}
+	pendingWarnings = nil
+	t.Setenv(configFilePathEnvKey, filepath.Join(t.TempDir(), defaultConfigFileName))
+	var buffer bytes.Buffer
+	log.SetOutput(&buffer)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	})
+
+	Init()
+	assert.Empty(t, buffer.String())
+
+	PrintWarnings()
+	assert.Contains(t, buffer.String(), "warning: the logger config file could not be opened.")
+	assert.Equal(t, 1, strings.Count(buffer.String(), "\n"))
+
+	PrintWarnings()
+	assert.Equal(t, 1, strings.Count(buffer.String(), "\n"))
+}
+
 func TestInit(t *testing.T) {
 	configOnce = sync.Once{}
 	setupConfigFile(t, "info", "cli")
@@ -72,3 +98,173 @@ func TestInit(t *testing.T) {
 	assert.Equal(t, "info", LoggerConfig.Logger.Level)
 	assert.Equal(t, "cli", LoggerConfig.Logger.OutputWriter)
 }
+
+func TestInitWithOutputs(t *testing.T) {
+	configOnce = sync.Once{}
+	setupConfigFile(t, "", "")
+	err := os.WriteFile(os.Getenv(configFilePathEnvKey), []byte(`logger:
+  outputs:
+    - level: warning
+      outputWriter: cli
+    - level: debug
+      outputWriter: jsonLines
+`), 0644)
+	if err != nil {
+		t.Fatalf("fatal: could not write yml file %v", err)
+	}
+	Init()
+
+	assert.Equal(t, []Output{
+		{Level: "warning", OutputWriter: "cli"},
+		{Level: "debug", OutputWriter: "jsonLines"},
+	}, LoggerConfig.Logger.Outputs)
+}
+
+func TestInitWithTransactionLogger(t *testing.T) {
+	configOnce = sync.Once{}
+	setupConfigFile(t, "", "")
+	err := os.WriteFile(os.Getenv(configFilePathEnvKey), []byte(`logger:
+  level: warning
+  outputWriter: cli
+transactionLogger:
+  level: debug
+  outputWriter: jsonLines
+  fileSuffix: _orders.jsonl
+  compress: false
+  maxBackups: 0
+  maxEntries: 100
+`), 0644)
+	if err != nil {
+		t.Fatalf("fatal: could not write yml file %v", err)
+	}
+	Init()
+
+	compress := false
+	maxBackups := 0
+	assert.Equal(t, TransactionLogger{
+		Level:        "debug",
+		OutputWriter: "jsonLines",
+		FileSuffix:   "_orders.jsonl",
+		Compress:     &compress,
+		MaxBackups:   &maxBackups,
+		MaxEntries:   100,
+	}, LoggerConfig.TransactionLogger)
+	assert.Equal(t, "warning", LoggerConfig.Logger.Level)
+}
+
+func TestTransactionLoggerConfigTableDriven(t *testing.T) {
+	enabled := true
+	disabled := false
+	unlimited := 0
+	three := 3
+	seven := 7
+	ten := 10
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected TransactionLogger
+	}{
+		{
+			name:     "unset",
+			config:   Config{},
+			expected: TransactionLogger{MaxSizeMB: &unlimited, Compress: &disabled, MaxBackups: &unlimited, MaxAgeDays: &unlimited},
+		},
+		{
+			name: "falls back to the logger",
+			config: Config{
+				Logger: Logger{
+					Level:            "warning",
+					OutputWriter:     "jsonFile",
+					OutputDir:        "logs",
+					MaxSizeMB:        10,
+					RotationInterval: "1h",
+					Compress:         true,
+					MaxBackups:       3,
+					MaxAgeDays:       7,
+				},
+			},
+			expected: TransactionLogger{
+				Level:            "warning",
+				OutputWriter:     "jsonFile",
+				OutputDir:        "logs",
+				MaxSizeMB:        &ten,
+				RotationInterval: "1h",
+				Compress:         &enabled,
+				MaxBackups:       &three,
+				MaxAgeDays:       &seven,
+			},
+		},
+		{
+			name: "overrides the logger",
+			config: Config{
+				Logger: Logger{
+					Level:        "warning",
+					OutputWriter: "cli",
+					OutputDir:    "logs",
+					MaxSizeMB:    10,
+					Compress:     true,
+					MaxBackups:   3,
+					MaxAgeDays:   7,
+				},
+				TransactionLogger: TransactionLogger{
+					Level:        "debug",
+					OutputWriter: "jsonLines",
+					FileSuffix:   "_orders.jsonl",
+					MaxSizeMB:    &three,
+					Compress:     &disabled,
+					MaxBackups:   &unlimited,
+					MaxAgeDays:   &unlimited,
+					FlushLevel:   "warning",
+					MaxEntries:   100,
+				},
+			},
+			expected: TransactionLogger{
+				Level:        "debug",
+				OutputWriter: "jsonLines",
+				OutputDir:    "logs",
+				FileSuffix:   "_orders.jsonl",
+				MaxSizeMB:    &three,
+				Compress:     &disabled,
+				MaxBackups:   &unlimited,
+				MaxAgeDays:   &unlimited,
+				FlushLevel:   "warning",
+				MaxEntries:   100,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.TransactionLoggerConfig())
+		})
+	}
+}
+
+func TestInitWithInvalidDurations(t *testing.T) {
+	configOnce = sync.Once{}
+	pendingWarnings = nil
+	setupConfigFile(t, "", "")
+	err := os.WriteFile(os.Getenv(configFilePathEnvKey), []byte(`transactionLogger:
+  stopTimeout: undefined
+  maxLifetime: 1 minute
+  rotationInterval: 1 week
+logger:
+  rotationInterval: 1 day
+`), 0644)
+	if err != nil {
+		t.Fatalf("fatal: could not write yml file %v", err)
+	}
+	Init()
+
+	assert.Equal(t, "", LoggerConfig.TransactionLogger.StopTimeout)
+	assert.Equal(t, "", LoggerConfig.TransactionLogger.MaxLifetime)
+	assert.Equal(t, "", LoggerConfig.Logger.RotationInterval)
+	assert.Equal(t, "", LoggerConfig.TransactionLogger.RotationInterval)
+	assert.Len(t, pendingWarnings, 4)
+	assert.Contains(t, pendingWarnings[0], "warning: the logger.rotationInterval duration could not be parsed")
+	assert.Contains(t, pendingWarnings[1], "warning: the transactionLogger.rotationInterval duration could not be parsed")
+	assert.Contains(t, pendingWarnings[2], "warning: the transactionLogger.stopTimeout duration could not be parsed")
+	assert.Contains(t, pendingWarnings[3], "warning: the transactionLogger.maxLifetime duration could not be parsed")
+	pendingWarnings = nil
+}
@@ -0,0 +1,195 @@
+package logging
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Overflow policies used by the asynchronous logging queue
+const (
+	OverflowBlock      OverflowPolicy = "block"      // wait until the queue has room
+	OverflowDropNewest OverflowPolicy = "dropNewest" // discard the log that is being added
+	OverflowDropOldest OverflowPolicy = "dropOldest" // discard the oldest queued log to make room
+)
+
+const (
+	defaultAsyncQueueSize = 1024
+)
+
+// A OverflowPolicy specifies what happens to a log when the asynchronous queue, or the buffer of a transaction, is full.
+type OverflowPolicy string
+
+// A asyncLogWriter is a bounded queue of logs, drained by a background worker that writes them to the output.
+type asyncLogWriter struct {
+	queue          chan *LoggerData
+	overflowPolicy OverflowPolicy
+	write          func(*LoggerData)
+
+	droppedLogs atomic.Uint64
+
+	closeMutex sync.RWMutex
+	closed     bool
+
+	pendingMutex sync.Mutex
+	pendingCond  *sync.Cond
+	pending      int
+
+	done chan struct{}
+}
+
+// newAsyncLogWriter creates an asynchronous queue that is not yet started.
+//
+// A queue size lower than 1 falls back to the default queue size.
+func newAsyncLogWriter(queueSize int, overflowPolicy OverflowPolicy) *asyncLogWriter {
+	if queueSize < 1 {
+		queueSize = defaultAsyncQueueSize
+	}
+	switch overflowPolicy {
+	case OverflowBlock, OverflowDropNewest, OverflowDropOldest:
+	default:
+		overflowPolicy = OverflowBlock
+	}
+
+	w := &asyncLogWriter{
+		queue:          make(chan *LoggerData, queueSize),
+		overflowPolicy: overflowPolicy,
+		done:           make(chan struct{}),
+	}
+	w.pendingCond = sync.NewCond(&w.pendingMutex)
+	return w
+}
+
+// start launches the background worker that hands every queued log to the write function.
+func (w *asyncLogWriter) start(write func(*LoggerData)) {
+	w.write = write
+	go w.run()
+}
+
+// run drains the queue until it is closed.
+func (w *asyncLogWriter) run() {
+	defer close(w.done)
+	for loggerData := range w.queue {
+		w.write(loggerData)
+		w.addPending(-1)
+	}
+}
+
+// enqueue adds a log to the queue, respecting the overflow policy.
+// It returns false if the queue is closed and the log was not accepted.
+//
+// enqueue is safe to call concurrently with other operations.
+func (w *asyncLogWriter) enqueue(loggerData *LoggerData) bool {
+	w.closeMutex.RLock()
+	defer w.closeMutex.RUnlock()
+	if w.closed {
+		return false
+	}
+
+	w.addPending(1)
+	switch w.overflowPolicy {
+	case OverflowDropNewest:
+		select {
+		case w.queue <- loggerData:
+		default:
+			w.drop()
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case w.queue <- loggerData:
+				return true
+			default:
+			}
+			select {
+			case <-w.queue:
+				w.drop()
+			default:
+			}
+		}
+	default:
+		w.queue <- loggerData
+	}
+	return true
+}
+
+// drop accounts for a log that was discarded because the queue was full.
+func (w *asyncLogWriter) drop() {
+	w.droppedLogs.Add(1)
+	w.addPending(-1)
+}
+
+// addPending updates the number of logs that were accepted but not yet written.
+func (w *asyncLogWriter) addPending(delta int) {
+	w.pendingMutex.Lock()
+	w.pending += delta
+	if w.pending == 0 {
+		w.pendingCond.Broadcast()
+	}
+	w.pendingMutex.Unlock()
+}
+
+// flush blocks until every accepted log was written to the output.
+func (w *asyncLogWriter) flush() {
+	w.pendingMutex.Lock()
+	for w.pending > 0 {
+		w.pendingCond.Wait()
+	}
+	w.pendingMutex.Unlock()
+}
+
+// close stops accepting logs, writes the remaining ones and waits for the background worker to exit.
+//
+// close is safe to call multiple times.
+func (w *asyncLogWriter) close() {
+	w.closeMutex.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.closeMutex.Unlock()
+	<-w.done
+}
+
+// WithAsyncOutput is a pre-defined "driver" that makes the logger hand the logs to a bounded queue,
+// drained by a background worker, instead of writing them synchronously.
+//
+// When the queue is full, the overflow policy decides if the caller blocks or which log is discarded.
+// Call Flush or Close before the program exits, so that no queued log is lost.
+func WithAsyncOutput(queueSize int, overflowPolicy OverflowPolicy) func(*logging) {
+	return func(l *logging) {
+		l.asyncWriter = newAsyncLogWriter(queueSize, overflowPolicy)
+	}
+}
+
+// Flush blocks until every log accepted so far was written to the output, then writes the buffered data of the file writers.
+// The file writers are shared by the whole process, so that the files of every logger are flushed, not only the ones of this logger.
+//
+// For a synchronous logger, only the file writers are flushed.
+func (l *logging) Flush() error {
+	if l.asyncWriter != nil {
+		l.asyncWriter.flush()
+	}
+	return FlushFileSinks()
+}
+
+// Close writes the queued logs and stops the background worker of an asynchronous logger,
+// then writes the buffered data and closes the files of the file writers.
+// The file writers are shared by the whole process, so that the files of every logger are closed, not only the ones of this logger:
+// no log is lost, every file writer opening its file again on its next write.
+// Logs registered after Close are written synchronously, the files being opened again on the next write.
+//
+// Close is safe to call multiple times.
+func (l *logging) Close() error {
+	if l.asyncWriter != nil {
+		l.asyncWriter.close()
+	}
+	return CloseFileSinks()
+}
+
+// DroppedLogs returns the number of logs discarded because the asynchronous queue was full.
+func (l *logging) DroppedLogs() uint64 {
+	if l.asyncWriter == nil {
+		return 0
+	}
+	return l.asyncWriter.droppedLogs.Load()
+}
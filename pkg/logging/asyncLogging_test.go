@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingLogOutputWrite returns an output writer that blocks its first write until blocked is closed, after sending to started,
+// then hands the logs to the given output writer.
+func blockingLogOutputWrite(outputWrite LogOutputWriter, started chan<- struct{}, blocked <-chan struct{}) LogOutputWriter {
+	var once sync.Once
+	return func(loggerData *LoggerData) error {
+		once.Do(func() {
+			started <- struct{}{}
+			<-blocked
+		})
+		return outputWrite(loggerData)
+	}
+}
+
+func TestWithAsyncOutput(t *testing.T) {
+	l := logging{}
+	WithAsyncOutput(10, OverflowDropOldest)(&l)
+	assert.NotNil(t, l.asyncWriter)
+	assert.Equal(t, 10, cap(l.asyncWriter.queue))
+	assert.Equal(t, OverflowDropOldest, l.asyncWriter.overflowPolicy)
+}
+
+func TestWithAsyncOutputInvalidValues(t *testing.T) {
+	l := logging{}
+	WithAsyncOutput(0, "undefined")(&l)
+	assert.Equal(t, defaultAsyncQueueSize, cap(l.asyncWriter.queue))
+	assert.Equal(t, OverflowBlock, l.asyncWriter.overflowPolicy)
+}
+
+func TestAsyncOutputBlock(t *testing.T) {
+	loggerOnce = sync.Once{}
+	var loggerData []*LoggerData
+	log := NewLog(WithAsyncOutput(1, OverflowBlock), WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)))
+
+	log.Info("test 1", nil)
+	log.Info("test 2", nil)
+	log.Info("test 3", nil)
+	err := log.Close()
+	if err != nil {
+		t.Fatalf("fatal: could not close the logger %v", err)
+	}
+
+	assert.Equal(t, []string{"test 1", "test 2", "test 3"}, loggerDataMessages(loggerData))
+	assert.Equal(t, uint64(0), log.DroppedLogs())
+}
+
+func TestAsyncOutputDropNewest(t *testing.T) {
+	loggerOnce = sync.Once{}
+	var loggerData []*LoggerData
+	started := make(chan struct{})
+	blocked := make(chan struct{})
+	log := NewLog(WithAsyncOutput(1, OverflowDropNewest), WithLogOutputWriter(blockingLogOutputWrite(recordingLoggerDataOutputWrite(&loggerData), started, blocked)))
+	t.Cleanup(func() {
+		log.Close()
+	})
+
+	log.Info("test 1", nil)
+	<-started
+	log.Info("test 2", nil)
+	log.Info("test 3", nil)
+	close(blocked)
+	log.Flush()
+
+	assert.Equal(t, []string{"test 1", "test 2"}, loggerDataMessages(loggerData))
+	assert.Equal(t, uint64(1), log.DroppedLogs())
+}
+
+func TestAsyncOutputDropOldest(t *testing.T) {
+	loggerOnce = sync.Once{}
+	var loggerData []*LoggerData
+	started := make(chan struct{})
+	blocked := make(chan struct{})
+	log := NewLog(WithAsyncOutput(1, OverflowDropOldest), WithLogOutputWriter(blockingLogOutputWrite(recordingLoggerDataOutputWrite(&loggerData), started, blocked)))
+	t.Cleanup(func() {
+		log.Close()
+	})
+
+	log.Info("test 1", nil)
+	<-started
+	log.Info("test 2", nil)
+	log.Info("test 3", nil)
+	close(blocked)
+	log.Flush()
+
+	assert.Equal(t, []string{"test 1", "test 3"}, loggerDataMessages(loggerData))
+	assert.Equal(t, uint64(1), log.DroppedLogs())
+}
+
+func TestAsyncOutputAfterClose(t *testing.T) {
+	loggerOnce = sync.Once{}
+	var loggerData []*LoggerData
+	log := NewLog(WithAsyncOutput(1, OverflowBlock), WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)))
+
+	log.Info("test 1", nil)
+	log.Close()
+	log.Close()
+	log.Info("test 2", nil)
+
+	assert.Equal(t, []string{"test 1", "test 2"}, loggerDataMessages(loggerData))
+}
+
+func TestSyncOutputFlushAndClose(t *testing.T) {
+	loggerOnce = sync.Once{}
+	log := NewLog()
+
+	assert.Nil(t, log.Flush())
+	assert.Nil(t, log.Close())
+	assert.Equal(t, uint64(0), log.DroppedLogs())
+}
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxCallerFrames = 16 // the frames inspected to find the caller, beyond the skipped ones
+)
+
+// A Caller is the location of the code that registered a log, recorded by the loggers capturing their callers (see WithCaller).
+type Caller struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"` // the function name, qualified by its package path
+}
+
+// WithCaller is a pre-defined "driver" that records the file, the line and the function of the code registering every log.
+// The skip depth is the number of additional frames to skip, e.g. 1 for a function wrapping the log methods,
+// so that the caller of the wrapper is recorded.
+//
+// Capturing the caller has a cost for every printed log, it is disabled by default.
+func WithCaller(skip int) func(*logging) {
+	return func(l *logging) {
+		l.caller = true
+		l.callerSkip = skip
+	}
+}
+
+// WithTransactionCaller is a pre-defined "driver" that records the file, the line and the function of the code adding every log to the transaction.
+// The skip depth is the number of additional frames to skip, e.g. 1 for a function wrapping the log methods,
+// so that the caller of the wrapper is recorded.
+//
+// Capturing the caller has a cost for every kept log, it is disabled by default.
+func WithTransactionCaller(skip int) func(*transactionLogging) {
+	return func(l *transactionLogging) {
+		l.caller = true
+		l.callerSkip = skip
+	}
+}
+
+// String renders the caller as its file, trimmed to its directory and name, followed by its line (e.g. "logging/logging.go:42").
+func (c *Caller) String() string {
+	return shortCallerFile(c.File) + ":" + strconv.Itoa(c.Line)
+}
+
+// captureCaller returns the location of the code calling the function that called captureCaller, skip frames higher.
+// The frames of the standard log package are skipped, so that the logs printed through StdLogger record the code printing them.
+func captureCaller(skip int) *Caller {
+	var pcs [maxCallerFrames]uintptr
+	n := runtime.Callers(skip+3, pcs[:]) // runtime.Callers, captureCaller and the function calling it
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "log.") {
+			return callerFromFrame(frame)
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// callerFromPC returns the location of the given program counter (e.g. the one of a slog record), nil if it is unset.
+func callerFromPC(pc uintptr) *Caller {
+	if pc == 0 {
+		return nil
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return callerFromFrame(frame)
+}
+
+// callerFromFrame converts a stack frame, nil if it is unknown.
+func callerFromFrame(frame runtime.Frame) *Caller {
+	if frame.PC == 0 {
+		return nil
+	}
+	return &Caller{File: frame.File, Line: frame.Line, Function: frame.Function}
+}
+
+// callerFields returns the caller and the function attributes rendered by the text writers, none if the caller was not captured.
+// The function is rendered without its package path (e.g. "logging.NewLog").
+func callerFields(caller *Caller) []Field {
+	if caller == nil {
+		return nil
+	}
+	return []Field{{Key: "caller", Value: caller.String()}, {Key: "function", Value: shortCallerFunction(caller.Function)}}
+}
+
+// shortCallerFile trims a file path to its directory and name.
+func shortCallerFile(file string) string {
+	i := strings.LastIndexByte(file, '/')
+	if i < 0 {
+		return file
+	}
+	if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+		return file[j+1:]
+	}
+	return file
+}
+
+// shortCallerFunction trims the package path of a function name, keeping the package name.
+func shortCallerFunction(function string) string {
+	return function[strings.LastIndexByte(function, '/')+1:]
+}
@@ -0,0 +1,144 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// callerLine returns the line of the code calling callerLine.
+func callerLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+// wrappedInfo is a function wrapping the log methods, whose caller is recorded with a skip depth of 1.
+func wrappedInfo(logger *logging, msg string) {
+	logger.Info(msg, nil)
+}
+
+func TestWithCaller(t *testing.T) {
+	var loggerData []*LoggerData
+	logger := NewLogInstance(WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)), WithLoggerLevel(LevelDebug), WithCaller(0))
+
+	logger.Info("info", nil)
+	infoLine := callerLine() - 1
+	logger.With(MetaData{"bound": 1}).WarningFields("warning")
+	warningLine := callerLine() - 1
+	logger.StdLogger(LevelError).Println("std")
+	stdLine := callerLine() - 1
+	slog.New(NewSlogHandler(logger)).Debug("slog")
+	slogLine := callerLine() - 1
+
+	assert.Len(t, loggerData, 4)
+	for i, line := range []int{infoLine, warningLine, stdLine, slogLine} {
+		assert.NotNil(t, loggerData[i].Caller, loggerData[i].Message)
+		assert.True(t, strings.HasSuffix(loggerData[i].Caller.File, "/logging/caller_test.go"), loggerData[i].Caller.File)
+		assert.Equal(t, line, loggerData[i].Caller.Line, loggerData[i].Message)
+		assert.Equal(t, "go-telemetry/pkg/logging.TestWithCaller", loggerData[i].Caller.Function, loggerData[i].Message)
+	}
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	tests := []struct {
+		name             string
+		skip             int
+		expectedFunction string
+	}{
+		{name: "the wrapper is recorded", skip: 0, expectedFunction: "go-telemetry/pkg/logging.wrappedInfo"},
+		{name: "the caller of the wrapper is recorded", skip: 1, expectedFunction: "go-telemetry/pkg/logging.TestWithCallerSkip.func1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var loggerData []*LoggerData
+			logger := NewLogInstance(WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)), WithLoggerLevel(LevelInfo), WithCaller(test.skip))
+
+			wrappedInfo(logger, "info")
+
+			assert.Len(t, loggerData, 1)
+			assert.Equal(t, test.expectedFunction, loggerData[0].Caller.Function)
+		})
+	}
+}
+
+func TestWithoutCaller(t *testing.T) {
+	var loggerData []*LoggerData
+	logger := NewLogInstance(WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)), WithLoggerLevel(LevelInfo))
+
+	logger.Info("info", nil)
+
+	assert.Len(t, loggerData, 1)
+	assert.Nil(t, loggerData[0].Caller)
+}
+
+func TestWithTransactionCaller(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var chunks []*TransactionLoggerData
+	tx, err := NewTransactionLog("caller", WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&chunks)), WithTransactionLoggerLevel(LevelDebug), WithTransactionCaller(0))
+	assert.NoError(t, err)
+	assert.NoError(t, tx.StartTransactionLogging())
+
+	tx.Info("info", nil)
+	infoLine := callerLine() - 1
+	sub, err := tx.StartSubTransaction("step")
+	assert.NoError(t, err)
+	sub.ErrorFields("error")
+	errorLine := callerLine() - 1
+	assert.NoError(t, sub.StopTransactionLogging())
+	assert.NoError(t, tx.StopTransactionLogging())
+
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, infoLine, chunks[0].TransactionLogs[0].Caller.Line)
+	assert.Equal(t, "go-telemetry/pkg/logging.TestWithTransactionCaller", chunks[0].TransactionLogs[0].Caller.Function)
+	assert.Equal(t, errorLine, chunks[0].SubTransactions[0].TransactionLogs[0].Caller.Line)
+}
+
+func TestFormatLoggerDataWithCaller(t *testing.T) {
+	loggerData := &LoggerData{
+		LoggerLevel: LevelInfo,
+		Timestamp:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message:     "test",
+		MetaData:    map[string]any{"a": 1},
+		Caller:      &Caller{File: "/src/go-telemetry/pkg/logging/logging.go", Line: 42, Function: "go-telemetry/pkg/logging.(*logging).Info"},
+	}
+
+	assert.Equal(t, logFormat(*loggerData)+" [a=1] [caller=logging/logging.go:42] [function=logging.(*logging).Info]", formatLoggerData(loggerData))
+	assert.Equal(t, `ts=2024-01-02T03:04:05Z level=info msg=test a=1 caller=logging/logging.go:42 function=logging.(*logging).Info`, formatLogfmtLoggerData(loggerData))
+
+	loggerDataBytes, err := json.Marshal(loggerData)
+	assert.NoError(t, err)
+	assert.Contains(t, string(loggerDataBytes), `"caller":{"file":"/src/go-telemetry/pkg/logging/logging.go","line":42,"function":"go-telemetry/pkg/logging.(*logging).Info"}`)
+}
+
+func TestSlogLogOutputWriteWithCaller(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := SlogLogOutputWrite(slog.NewJSONHandler(&buffer, nil))
+
+	err := writer(&LoggerData{LoggerLevel: LevelInfo, Timestamp: time.Now(), Message: "test", Caller: &Caller{File: "/src/main.go", Line: 7, Function: "main.main"}})
+
+	assert.NoError(t, err)
+	assert.Contains(t, buffer.String(), `"source":{"function":"main.main","file":"/src/main.go","line":7}`)
+}
+
+func TestShortCallerFile(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{file: "/src/go-telemetry/pkg/logging/logging.go", expected: "logging/logging.go"},
+		{file: "logging/logging.go", expected: "logging/logging.go"},
+		{file: "logging.go", expected: "logging.go"},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			assert.Equal(t, test.expected, shortCallerFile(test.file))
+		})
+	}
+}
@@ -1,28 +1,39 @@
 package logging
 
-// Logger levels used by logger driver.
+import "sync"
+
+// Logger levels used by logger driver, from the least to the most severe.
+// A logger prints the logs at least as severe as its level, no log reaches the level Off.
 const (
-	LevelOff     loggerLevel = "off"     // 0
-	LevelInfo    loggerLevel = "info"    // 1
-	LevelWarning loggerLevel = "warning" // 2
-	LevelError   loggerLevel = "error"   // 3
-	LevelDebug   loggerLevel = "debug"   // 4
+	LevelTrace   Level = "trace"   // 1
+	LevelDebug   Level = "debug"   // 2
+	LevelInfo    Level = "info"    // 3
+	LevelWarning Level = "warning" // 4
+	LevelError   Level = "error"   // 5
+	LevelPanic   Level = "panic"   // 6
+	LevelFatal   Level = "fatal"   // 7
+	LevelOff     Level = "off"     // 8
 )
 
-// Logger levels numbers used when comparing string log levels
+// Logger levels numbers used when comparing string log levels, increasing with the severity
 const (
-	levelOffInt     int = 0
-	levelInfoInt    int = 1
-	levelWarningInt int = 2
-	levelErrorInt   int = 3
-	levelDebugInt   int = 4
+	levelTraceInt   int = 1
+	levelDebugInt   int = 2
+	levelInfoInt    int = 3
+	levelWarningInt int = 4
+	levelErrorInt   int = 5
+	levelPanicInt   int = 6
+	levelFatalInt   int = 7
+	levelOffInt     int = 8
 )
 
 // Output writers types that resemble the way the logs are printed
 const (
-	cli      OutputWriterType = "cli"
-	jsonFile OutputWriterType = "jsonFile"
-	textFile OutputWriterType = "textFile"
+	cli       OutputWriterType = "cli"
+	jsonFile  OutputWriterType = "jsonFile"
+	textFile  OutputWriterType = "textFile"
+	logfmt    OutputWriterType = "logfmt"
+	jsonLines OutputWriterType = "jsonLines"
 )
 
 const (
@@ -39,25 +50,77 @@ const (
 	fileTimestampFormat = "2006-01-02"
 )
 
-type loggerLevel string
+var fileMutexes sync.Map // file name -> *sync.Mutex, shared by every output writer that writes the same file
 
 // A OutputWriterType is a output writer driver identifier.
 type OutputWriterType string
 
-// convertLoggerLevelToInt converts loggerLevel type to its corresponding number value
-func convertLoggerLevelToInt(loggerLevel loggerLevel) int {
+// convertLoggerLevelToInt converts Level type to its corresponding number value, an unknown level being Info
+func convertLoggerLevelToInt(loggerLevel Level) int {
 	switch loggerLevel {
-	case LevelOff:
-		return levelOffInt
+	case LevelTrace:
+		return levelTraceInt
+	case LevelDebug:
+		return levelDebugInt
 	case LevelInfo:
 		return levelInfoInt
 	case LevelWarning:
 		return levelWarningInt
 	case LevelError:
 		return levelErrorInt
-	case LevelDebug:
-		return levelDebugInt
+	case LevelPanic:
+		return levelPanicInt
+	case LevelFatal:
+		return levelFatalInt
+	case LevelOff:
+		return levelOffInt
 	default:
 		return levelInfoInt
 	}
 }
+
+// loggerLevelReached reports whether a log of the given level is at least as severe as the threshold level (e.g. the logger level).
+// A log of level Off never reaches a threshold, as no log reaches the threshold Off.
+func loggerLevelReached(loggerLevel Level, threshold Level) bool {
+	return loggerLevel != LevelOff && convertLoggerLevelToInt(loggerLevel) >= convertLoggerLevelToInt(threshold)
+}
+
+// lockFile locks the given file for all the output writers that write to it, and returns the unlock function.
+func lockFile(fileName string) func() {
+	mutex, _ := fileMutexes.LoadOrStore(fileName, &sync.Mutex{})
+	mutex.(*sync.Mutex).Lock()
+	return mutex.(*sync.Mutex).Unlock
+}
+
+// mergeMetaData returns a new MetaData that holds the attributes of both arguments.
+// The overrides have a higher priority than the base attributes with the same key.
+func mergeMetaData(base MetaData, overrides MetaData) MetaData {
+	if base == nil && overrides == nil {
+		return nil
+	}
+	merged := make(MetaData, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// fieldsToMetaData converts the ordered fields into MetaData and the insertion order of its keys.
+// If a key is repeated, the last value is kept at the position of the first occurrence.
+func fieldsToMetaData(fields []Field) (MetaData, []string) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	metaData := make(MetaData, len(fields))
+	metaDataOrder := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if _, ok := metaData[field.Key]; !ok {
+			metaDataOrder = append(metaDataOrder, field.Key)
+		}
+		metaData[field.Key] = field.Value
+	}
+	return metaData, metaDataOrder
+}
@@ -5,10 +5,14 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"testing"
+	"time"
 )
 
 func setupTestEnvironment(t *testing.T, testDirName string) {
+	// load the configuration once, so that it does not override the test configuration later
+	config.Init()
 	config.LoggerConfig = &config.Config{}
 	_, file, _, ok := runtime.Caller(0)
 	if !ok {
@@ -26,10 +30,65 @@ func setupTestEnvironment(t *testing.T, testDirName string) {
 }
 
 func cleanup(t *testing.T, logFiles []string) {
+	err := CloseFileSinks()
+	if err != nil {
+		t.Errorf("error: could not close the file sinks %v", err)
+	}
+	forgetFileSinks()
 	for _, logFile := range logFiles {
-		err := os.Remove(filepath.Join(config.LoggerConfig.Logger.OutputDir, logFile))
+		err = os.Remove(filepath.Join(config.LoggerConfig.Logger.OutputDir, logFile))
 		if err != nil {
 			t.Errorf("error: could not delete test artifact test/%s %v", logFile, err)
 		}
 	}
 }
+
+// forgetFileSinks drops the shared file sinks, so that the next test creates its own sinks using its own rotation policy.
+func forgetFileSinks() {
+	fileSinks.Range(func(key, _ any) bool {
+		fileSinks.Delete(key)
+		return true
+	})
+}
+
+// recordedMutex guards the slices of the recording output writers,
+// for the tests that read them while the logs are written in the background (e.g. by the timer of an expiring transaction).
+var recordedMutex sync.Mutex
+
+// recordingLoggerDataOutputWrite returns an output writer that records every written log.
+func recordingLoggerDataOutputWrite(loggerData *[]*LoggerData) LogOutputWriter {
+	return func(data *LoggerData) error {
+		recordedMutex.Lock()
+		defer recordedMutex.Unlock()
+		*loggerData = append(*loggerData, data)
+		return nil
+	}
+}
+
+// loggerDataMessages returns the messages of the logs.
+func loggerDataMessages(loggerData []*LoggerData) []string {
+	var messages []string
+	for _, data := range loggerData {
+		messages = append(messages, data.Message)
+	}
+	return messages
+}
+
+// recordingChunksTransactionLogOutputWrite returns an output writer that records every chunk written for a transaction.
+func recordingChunksTransactionLogOutputWrite(chunks *[]*TransactionLoggerData) TransactionLogOutputWriter {
+	return func(_ string, _ time.Time, _ time.Time, data *TransactionLoggerData) error {
+		recordedMutex.Lock()
+		defer recordedMutex.Unlock()
+		*chunks = append(*chunks, data)
+		return nil
+	}
+}
+
+// transactionLogMessages returns the messages of the logs of a transaction, without its sub-transactions.
+func transactionLogMessages(transactionLoggerData *TransactionLoggerData) []string {
+	var messages []string
+	for _, transactionLog := range transactionLoggerData.TransactionLogs {
+		messages = append(messages, transactionLog.Message)
+	}
+	return messages
+}
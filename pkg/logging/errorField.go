@@ -0,0 +1,213 @@
+package logging
+
+import (
+	"log/slog"
+	"maps"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	errorKey         = "error" // the attribute key of the Err field
+	maxErrorChain    = 32      // the wrapped errors recorded by Err, protecting from cyclic chains
+	maxErrorStackLen = 32      // the frames recorded by the error stacks
+)
+
+// An ErrorValue is the log attribute recording an error, created by Err.
+// The JSON writers write it as an object, the text writers as its message followed by its chain and its stack,
+// the logfmt writers as a pair for its message followed by the pairs of its chain and its stack.
+//
+// An ErrorValue is an error wrapping the recorded error, so that the custom output writers can still use errors.Is and errors.As.
+type ErrorValue struct {
+	Message string   `json:"message"`
+	Chain   []string `json:"chain,omitempty"` // the messages of the wrapped errors, depth first
+	Stack   []Caller `json:"stack,omitempty"` // the code that registered the log, innermost first, see WithErrorStack
+	err     error    // the recorded error
+}
+
+// Err returns the "error" attribute, recording the message of the error and the messages of the errors it wraps,
+// following errors.Unwrap and errors.Join. A nil error is recorded as nil.
+//
+// The error values given in MetaData (e.g. MetaData{"err": err}) are recorded the same way, under their own key.
+//
+// The logs of level Error record the stack as well, if the logger captures the error stacks (see WithErrorStack).
+func Err(err error) Field {
+	if err == nil {
+		return Field{Key: errorKey, Value: nil}
+	}
+	return Field{Key: errorKey, Value: &ErrorValue{Message: err.Error(), Chain: errorChain(err), err: err}}
+}
+
+// WithErrorStack is a pre-defined "driver" that records the stack of the code registering the logs of level Error,
+// in their Err attributes and error values. The frames skipped by WithCaller are skipped as well.
+//
+// Capturing the stack has a cost for every printed Error log with an Err attribute, it is disabled by default.
+func WithErrorStack() func(*logging) {
+	return func(l *logging) {
+		l.errorStack = true
+	}
+}
+
+// WithTransactionErrorStack is a pre-defined "driver" that records the stack of the code adding the logs of level Error to the transaction,
+// in their Err attributes and error values. The frames skipped by WithTransactionCaller are skipped as well.
+//
+// Capturing the stack has a cost for every kept Error log with an Err attribute, it is disabled by default.
+func WithTransactionErrorStack() func(*transactionLogging) {
+	return func(l *transactionLogging) {
+		l.errorStack = true
+	}
+}
+
+// errorChain returns the messages of the errors wrapped by the error, depth first.
+func errorChain(err error) []string {
+	var chain []string
+	var walk func(err error)
+	walk = func(err error) {
+		var wrapped []error
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			wrapped = []error{e.Unwrap()}
+		case interface{ Unwrap() []error }:
+			wrapped = e.Unwrap()
+		}
+		for _, inner := range wrapped {
+			if inner == nil || len(chain) >= maxErrorChain {
+				continue
+			}
+			chain = append(chain, inner.Error())
+			walk(inner)
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// withErrorValues returns the attributes where the error values are recorded as Err attributes (e.g. MetaData{"err": err}),
+// so that the JSON writers write their message and chain, and the error stacks apply to them.
+// The given attributes are returned as is if they hold no error, otherwise a copy is returned: they might be reused by the caller.
+func withErrorValues(metaData MetaData) MetaData {
+	var converted MetaData
+	for k, v := range metaData {
+		err, ok := v.(error)
+		if _, isErrorValue := v.(*ErrorValue); !ok || isErrorValue || isNilPointer(v) {
+			continue
+		}
+		if converted == nil {
+			converted = make(MetaData, len(metaData))
+			maps.Copy(converted, metaData)
+		}
+		converted[k] = Err(err).Value
+	}
+	if converted == nil {
+		return metaData
+	}
+	return converted
+}
+
+// isNilPointer reports whether the value is a typed nil pointer, whose methods must not be called.
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// Error returns the message of the recorded error.
+func (errorValue *ErrorValue) Error() string {
+	return errorValue.Message
+}
+
+// Unwrap returns the recorded error, nil if the ErrorValue was not created by Err.
+func (errorValue *ErrorValue) Unwrap() error {
+	return errorValue.err
+}
+
+// LogValue implements slog.LogValuer, so that the slog handlers write the Err attributes as a group holding the message, the chain and the stack.
+func (errorValue *ErrorValue) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("message", errorValue.Message)}
+	if len(errorValue.Chain) > 0 {
+		attrs = append(attrs, slog.Any("chain", errorValue.Chain))
+	}
+	if len(errorValue.Stack) > 0 {
+		attrs = append(attrs, slog.Any("stack", errorValue.Stack))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// hasErrorValue reports whether the attributes hold an Err attribute.
+func hasErrorValue(metaData MetaData) bool {
+	for _, v := range metaData {
+		if _, ok := v.(*ErrorValue); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// withErrorStack returns a copy of the attributes whose Err attributes record the stack.
+// The given attributes are left unchanged, they might be reused by the caller.
+func withErrorStack(metaData MetaData, stack []Caller) MetaData {
+	stacked := make(MetaData, len(metaData))
+	for k, v := range metaData {
+		if errorValue, ok := v.(*ErrorValue); ok {
+			withStack := *errorValue
+			withStack.Stack = stack
+			v = &withStack
+		}
+		stacked[k] = v
+	}
+	return stacked
+}
+
+// captureStack returns the stack from the code calling the function that called captureStack, skip frames higher.
+func captureStack(skip int) []Caller {
+	var pcs [maxErrorStackLen]uintptr
+	n := runtime.Callers(skip+3, pcs[:]) // runtime.Callers, captureStack and the function calling it
+	stack := make([]Caller, 0, n)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.PC != 0 {
+			stack = append(stack, Caller{File: frame.File, Line: frame.Line, Function: frame.Function})
+		}
+		if !more {
+			return stack
+		}
+	}
+}
+
+// formatErrorValue renders an Err attribute as text: its message, followed by the messages of the wrapped errors and its stack if they are set
+// (e.g. "read config: not found" caused by ["not found"] at [main.load(app/config.go:12) main.main(app/main.go:5)]).
+func formatErrorValue(errorValue *ErrorValue) string {
+	var sb strings.Builder
+	sb.WriteString(quoteIfNeeded(errorValue.Message))
+	if len(errorValue.Chain) > 0 {
+		sb.WriteString(" caused by " + formatValue(errorValue.Chain))
+	}
+	if len(errorValue.Stack) > 0 {
+		sb.WriteString(" at " + formatErrorStack(errorValue.Stack))
+	}
+	return sb.String()
+}
+
+// writeLogfmtErrorValue appends an Err attribute to a logfmt line as native pairs: its message under its key, the messages of the wrapped errors
+// under indexed chain keys and its stack under a stack key (e.g. err="read config: not found" err.chain.0="not found" err.stack=[main.load(app/config.go:12)]).
+func writeLogfmtErrorValue(sb *strings.Builder, key string, errorValue *ErrorValue) {
+	writeLogfmtPair(sb, key, errorValue.Message)
+	for i, message := range errorValue.Chain {
+		writeLogfmtPair(sb, key+".chain."+strconv.Itoa(i), message)
+	}
+	if len(errorValue.Stack) > 0 {
+		writeLogfmtPair(sb, key+".stack", formatErrorStack(errorValue.Stack))
+	}
+}
+
+// formatErrorStack renders the stack of an Err attribute as its frames, each one being the function followed by its file and line
+// (e.g. [main.load(app/config.go:12) main.main(app/main.go:5)]).
+func formatErrorStack(stack []Caller) string {
+	frames := make([]string, len(stack))
+	for i, frame := range stack {
+		frames[i] = shortCallerFunction(frame.Function) + "(" + frame.String() + ")"
+	}
+	return "[" + strings.Join(frames, " ") + "]"
+}
@@ -0,0 +1,229 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErr(t *testing.T) {
+	root := errors.New("not found")
+	wrapped := fmt.Errorf("read config: %w", fmt.Errorf("open file: %w", root))
+	joined := errors.Join(fmt.Errorf("first: %w", root), errors.New("second"))
+	tests := []struct {
+		name     string
+		err      error
+		expected any
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "error without chain",
+			err:      root,
+			expected: &ErrorValue{Message: "not found", err: root},
+		},
+		{
+			name:     "wrapped error",
+			err:      wrapped,
+			expected: &ErrorValue{Message: "read config: open file: not found", Chain: []string{"open file: not found", "not found"}, err: wrapped},
+		},
+		{
+			name:     "joined errors",
+			err:      joined,
+			expected: &ErrorValue{Message: "first: not found\nsecond", Chain: []string{"first: not found", "not found", "second"}, err: joined},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			field := Err(test.err)
+
+			assert.Equal(t, "error", field.Key)
+			assert.Equal(t, test.expected, field.Value)
+		})
+	}
+}
+
+func TestWithErrorStack(t *testing.T) {
+	var loggerData []*LoggerData
+	logger := NewLogInstance(WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)), WithLoggerLevel(LevelDebug), WithErrorStack())
+	field := Err(errors.New("failed"))
+
+	logger.ErrorFields("error", field)
+	errorLine := callerLine() - 1
+	logger.WarningFields("warning", field)
+	logger.Error("without Err", MetaData{"id": 1})
+
+	assert.Len(t, loggerData, 3)
+	errorValue := loggerData[0].MetaData["error"].(*ErrorValue)
+	assert.NotEmpty(t, errorValue.Stack)
+	assert.Equal(t, "go-telemetry/pkg/logging.TestWithErrorStack", errorValue.Stack[0].Function)
+	assert.Equal(t, errorLine, errorValue.Stack[0].Line)
+	assert.Nil(t, field.Value.(*ErrorValue).Stack, "the given attribute is left unchanged")
+	assert.Nil(t, loggerData[1].MetaData["error"].(*ErrorValue).Stack, "the stack is only recorded at level Error")
+	assert.Equal(t, MetaData{"id": 1}, loggerData[2].MetaData)
+}
+
+func TestWithoutErrorStack(t *testing.T) {
+	var loggerData []*LoggerData
+	logger := NewLogInstance(WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)), WithLoggerLevel(LevelDebug))
+
+	logger.ErrorFields("error", Err(errors.New("failed")))
+
+	assert.Len(t, loggerData, 1)
+	assert.Nil(t, loggerData[0].MetaData["error"].(*ErrorValue).Stack)
+}
+
+func TestWithTransactionErrorStack(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var chunks []*TransactionLoggerData
+	tx, err := NewTransactionLog("error stack", WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&chunks)),
+		WithTransactionLoggerLevel(LevelDebug), WithTransactionErrorStack())
+	assert.NoError(t, err)
+	assert.NoError(t, tx.StartTransactionLogging())
+
+	tx.ErrorFields("error", Err(errors.New("failed")))
+	errorLine := callerLine() - 1
+	assert.NoError(t, tx.StopTransactionLogging())
+
+	assert.Len(t, chunks, 1)
+	errorValue := chunks[0].TransactionLogs[0].MetaData["error"].(*ErrorValue)
+	assert.Equal(t, "go-telemetry/pkg/logging.TestWithTransactionErrorStack", errorValue.Stack[0].Function)
+	assert.Equal(t, errorLine, errorValue.Stack[0].Line)
+}
+
+func TestFormatErrorValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		errorValue *ErrorValue
+		expected   string
+	}{
+		{
+			name:       "message",
+			errorValue: &ErrorValue{Message: "failed"},
+			expected:   "failed",
+		},
+		{
+			name:       "message with chain",
+			errorValue: &ErrorValue{Message: "read config: not found", Chain: []string{"not found"}},
+			expected:   `"read config: not found" caused by ["not found"]`,
+		},
+		{
+			name: "message with stack",
+			errorValue: &ErrorValue{Message: "failed", Stack: []Caller{
+				{File: "/src/app/config.go", Line: 12, Function: "example.com/app.load"},
+				{File: "/src/app/main.go", Line: 5, Function: "example.com/app.main"},
+			}},
+			expected: "failed at [app.load(app/config.go:12) app.main(app/main.go:5)]",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, formatValue(test.errorValue))
+		})
+	}
+}
+
+func TestFormatLoggerDataWithErr(t *testing.T) {
+	metaData, metaDataOrder := fieldsToMetaData([]Field{Err(fmt.Errorf("read config: %w", errors.New("not found")))})
+	loggerData := &LoggerData{LoggerLevel: LevelError, Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Message: "test", MetaData: metaData, MetaDataOrder: metaDataOrder}
+
+	assert.Equal(t, logFormat(*loggerData)+` [error="read config: not found" caused by ["not found"]]`, formatLoggerData(loggerData))
+
+	loggerDataBytes, err := json.Marshal(loggerData)
+	assert.NoError(t, err)
+	assert.Contains(t, string(loggerDataBytes), `"metaData":{"error":{"message":"read config: not found","chain":["not found"]}}`)
+}
+
+func TestFormatLogfmtLoggerDataWithErr(t *testing.T) {
+	errorValue := &ErrorValue{Message: "read config: open file: not found", Chain: []string{"open file: not found", "not found"}, Stack: []Caller{
+		{File: "/src/app/config.go", Line: 12, Function: "example.com/app.load"},
+		{File: "/src/app/main.go", Line: 5, Function: "example.com/app.main"},
+	}}
+	loggerData := &LoggerData{LoggerLevel: LevelError, Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Message: "test", MetaData: MetaData{"err": errorValue, "id": 1}}
+
+	assert.Equal(t, `ts=2024-01-02T03:04:05Z level=error msg=test err="read config: open file: not found" err.chain.0="open file: not found" err.chain.1="not found" `+
+		`err.stack="[app.load(app/config.go:12) app.main(app/main.go:5)]" id=1`, formatLogfmtLoggerData(loggerData))
+}
+
+func TestErrorValuesInMetaData(t *testing.T) {
+	var loggerData []*LoggerData
+	logger := NewLogInstance(WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)), WithLoggerLevel(LevelDebug), WithErrorStack())
+	var nilError *json.SyntaxError
+	metaData := MetaData{"err": fmt.Errorf("wrap: %w", errors.New("not found")), "nil": nilError, "id": 1}
+
+	logger.Error("error", metaData)
+	errorLine := callerLine() - 1
+
+	assert.Len(t, loggerData, 1)
+	errorValue := loggerData[0].MetaData["err"].(*ErrorValue)
+	assert.Equal(t, "wrap: not found", errorValue.Message)
+	assert.Equal(t, []string{"not found"}, errorValue.Chain)
+	assert.Equal(t, errorLine, errorValue.Stack[0].Line)
+	assert.Equal(t, nilError, loggerData[0].MetaData["nil"])
+	assert.Equal(t, 1, loggerData[0].MetaData["id"])
+	assert.IsType(t, fmt.Errorf("wrap: %w", errors.New("not found")), metaData["err"], "the given attributes are left unchanged")
+
+	loggerDataBytes, err := json.Marshal(loggerData[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(loggerDataBytes), `"err":{"message":"wrap: not found","chain":["not found"],"stack":[`)
+}
+
+func TestErrorValuesInBoundMetaData(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var chunks []*TransactionLoggerData
+	tx, err := NewTransactionLog("error values", WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&chunks)))
+	assert.NoError(t, err)
+	assert.NoError(t, tx.StartTransactionLogging())
+
+	cause, failed := errors.New("not found"), errors.New("failed")
+	tx.With(MetaData{"cause": cause}).Info("info", MetaData{"err": failed})
+	assert.NoError(t, tx.StopTransactionLogging())
+
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, &ErrorValue{Message: "not found", err: cause}, chunks[0].TransactionLogs[0].MetaData["cause"])
+	assert.Equal(t, &ErrorValue{Message: "failed", err: failed}, chunks[0].TransactionLogs[0].MetaData["err"])
+}
+
+func TestErrorValuesUnwrap(t *testing.T) {
+	var loggerData []*LoggerData
+	logger := NewLogInstance(WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)), WithLoggerLevel(LevelDebug), WithErrorStack())
+	root := errors.New("not found")
+	var syntaxError *json.SyntaxError
+	err := json.Unmarshal([]byte("{"), &map[string]any{})
+	assert.ErrorAs(t, err, &syntaxError)
+
+	logger.ErrorFields("error", Err(fmt.Errorf("read config: %w", root)), Field{Key: "cause", Value: err})
+
+	assert.Len(t, loggerData, 1)
+	errorValue, ok := loggerData[0].MetaData["error"].(error)
+	assert.True(t, ok)
+	assert.ErrorIs(t, errorValue, root, "the error values wrap the recorded errors, including with their stack")
+	assert.Equal(t, "read config: not found", errorValue.Error())
+	cause, ok := loggerData[0].MetaData["cause"].(error)
+	assert.True(t, ok)
+	var recorded *json.SyntaxError
+	assert.ErrorAs(t, cause, &recorded)
+	assert.Same(t, syntaxError, recorded)
+	assert.Nil(t, (&ErrorValue{Message: "not found"}).Unwrap())
+}
+
+func TestErrorValueLogValue(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := SlogLogOutputWrite(slog.NewJSONHandler(&buffer, nil))
+	metaData, metaDataOrder := fieldsToMetaData([]Field{Err(fmt.Errorf("read config: %w", errors.New("not found")))})
+
+	err := writer(&LoggerData{LoggerLevel: LevelError, Timestamp: time.Now(), Message: "test", MetaData: metaData, MetaDataOrder: metaDataOrder})
+
+	assert.NoError(t, err)
+	assert.Contains(t, buffer.String(), `"error":{"message":"read config: not found","chain":["not found"]}`)
+}
@@ -0,0 +1,118 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+)
+
+var exit = os.Exit // replaced by the tests of Fatal
+
+// Panic registers a log of level Panic, with a message and additional attributes,
+// then flushes the logger (see Flush) and panics with the message, whatever the logger level.
+//
+// If no attributes, use nil as MetaData
+func (l *logging) Panic(msg string, v MetaData) {
+	l.processLoggerData(LevelPanic, msg, v, nil)
+	l.flushBeforeExit()
+	panic(msg)
+}
+
+// PanicFields registers a log of level Panic, with a message and additional attributes kept in the given order,
+// then flushes the logger (see Flush) and panics with the message, whatever the logger level.
+func (l *logging) PanicFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelPanic, msg, metaData, metaDataOrder)
+	l.flushBeforeExit()
+	panic(msg)
+}
+
+// Fatal registers a log of level Fatal, with a message and additional attributes,
+// then flushes the logger (see Flush) and exits the program with the status 1, whatever the logger level.
+// The deferred functions are not run.
+//
+// If no attributes, use nil as MetaData
+func (l *logging) Fatal(msg string, v MetaData) {
+	l.processLoggerData(LevelFatal, msg, v, nil)
+	l.flushBeforeExit()
+	exit(1)
+}
+
+// FatalFields registers a log of level Fatal, with a message and additional attributes kept in the given order,
+// then flushes the logger (see Flush) and exits the program with the status 1, whatever the logger level.
+// The deferred functions are not run.
+func (l *logging) FatalFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelFatal, msg, metaData, metaDataOrder)
+	l.flushBeforeExit()
+	exit(1)
+}
+
+// flushBeforeExit writes the queued logs and the buffered data of the file writers, printing the error if it fails.
+func (l *logging) flushBeforeExit() {
+	err := l.Flush()
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+// Panic registers a log of level Panic, for a specific transaction, with a message and additional attributes,
+// then flushes the file writers (see FlushFileSinks) and panics with the message, whatever the transaction log level.
+//
+// The transaction is not stopped: it is written by RunInTransaction, which recovers the panic, or by the deferred call stopping it.
+//
+// If no attributes, use nil as MetaData
+func (l *transactionLogging) Panic(msg string, v MetaData) {
+	l.processLoggerData(LevelPanic, msg, v, nil)
+	flushFileSinksBeforeExit()
+	panic(msg)
+}
+
+// PanicFields registers a log of level Panic, for a specific transaction, with a message and additional attributes kept in the given order,
+// then flushes the file writers (see FlushFileSinks) and panics with the message, whatever the transaction log level.
+//
+// The transaction is not stopped: it is written by RunInTransaction, which recovers the panic, or by the deferred call stopping it.
+func (l *transactionLogging) PanicFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelPanic, msg, metaData, metaDataOrder)
+	flushFileSinksBeforeExit()
+	panic(msg)
+}
+
+// Fatal registers a log of level Fatal, for a specific transaction, with a message and additional attributes,
+// then stops the root transaction with TransactionStatusError, flushes the file writers (see FlushFileSinks)
+// and exits the program with the status 1, whatever the transaction log level. The deferred functions are not run.
+//
+// If no attributes, use nil as MetaData
+func (l *transactionLogging) Fatal(msg string, v MetaData) {
+	l.processLoggerData(LevelFatal, msg, v, nil)
+	l.stopBeforeExit(msg)
+	exit(1)
+}
+
+// FatalFields registers a log of level Fatal, for a specific transaction, with a message and additional attributes kept in the given order,
+// then stops the root transaction with TransactionStatusError, flushes the file writers (see FlushFileSinks)
+// and exits the program with the status 1, whatever the transaction log level. The deferred functions are not run.
+func (l *transactionLogging) FatalFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelFatal, msg, metaData, metaDataOrder)
+	l.stopBeforeExit(msg)
+	exit(1)
+}
+
+// stopBeforeExit stops the root transaction with TransactionStatusError, so that it is written, then flushes the file writers.
+// The sub-transactions that were not stopped end with the transaction.
+func (l *transactionLogging) stopBeforeExit(msg string) {
+	err := l.root().StopTransactionLoggingWithStatus(TransactionStatusError, msg)
+	if err != nil {
+		fmt.Println(err)
+	}
+	flushFileSinksBeforeExit()
+}
+
+// flushFileSinksBeforeExit writes the buffered data of the file writers, printing the error if it fails.
+func flushFileSinksBeforeExit() {
+	err := FlushFileSinks()
+	if err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordExit replaces the exit of Fatal by a panic recording its status, restored at the end of the test.
+// The recorded status is -1 if Fatal did not exit.
+func recordExit(fn func()) (status int) {
+	defer func(restore func(int)) { exit = restore }(exit)
+	exit = func(code int) {
+		panic(exitStatus(code))
+	}
+
+	status = -1
+	defer func() {
+		if code, ok := recover().(exitStatus); ok {
+			status = int(code)
+		}
+	}()
+	fn()
+	return status
+}
+
+// An exitStatus is the panic value raised by the exit replaced by recordExit.
+type exitStatus int
+
+func TestPanic(t *testing.T) {
+	tests := []struct {
+		name        string
+		loggerLevel Level
+		panic       func(l *logging)
+		expectedLen int
+	}{
+		{name: "Panic", loggerLevel: LevelError, panic: func(l *logging) { l.Panic("test panic", MetaData{"id": 1}) }, expectedLen: 1},
+		{name: "PanicFields", loggerLevel: LevelError, panic: func(l *logging) { l.PanicFields("test panic", Field{"id", 1}) }, expectedLen: 1},
+		{name: "disabled level", loggerLevel: LevelFatal, panic: func(l *logging) { l.Panic("test panic", nil) }, expectedLen: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var loggerData []*LoggerData
+			logger := NewLogInstance(WithLoggerLevel(tt.loggerLevel), WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)))
+
+			assert.PanicsWithValue(t, "test panic", func() { tt.panic(logger) })
+
+			assert.Len(t, loggerData, tt.expectedLen)
+			if tt.expectedLen > 0 {
+				assert.Equal(t, LevelPanic, loggerData[0].LoggerLevel)
+				assert.Equal(t, 1, loggerData[0].MetaData["id"])
+			}
+		})
+	}
+}
+
+func TestFatal(t *testing.T) {
+	tests := []struct {
+		name  string
+		fatal func(l *logging)
+	}{
+		{name: "Fatal", fatal: func(l *logging) { l.Fatal("test fatal", MetaData{"id": 1}) }},
+		{name: "FatalFields", fatal: func(l *logging) { l.FatalFields("test fatal", Field{"id", 1}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var loggerData []*LoggerData
+			logger := NewLogInstance(WithLoggerLevel(LevelInfo), WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)), WithAsyncOutput(10, OverflowBlock))
+
+			status := recordExit(func() { tt.fatal(logger) })
+
+			assert.Equal(t, 1, status)
+			assert.Len(t, loggerData, 1, "the queued log is written before exiting")
+			assert.Equal(t, LevelFatal, loggerData[0].LoggerLevel)
+			assert.NoError(t, logger.Close())
+		})
+	}
+}
+
+func TestTransactionFatal(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var chunks []*TransactionLoggerData
+	tx, err := NewTransactionLog(testTransactionId, WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&chunks)))
+	assert.NoError(t, err)
+	assert.NoError(t, tx.StartTransactionLogging())
+	sub, err := tx.StartSubTransaction("step")
+	assert.NoError(t, err)
+
+	status := recordExit(func() { sub.FatalFields("test fatal", Field{"id", 1}) })
+
+	assert.Equal(t, 1, status)
+	assert.Len(t, chunks, 1, "the transaction is written before exiting")
+	assert.Equal(t, TransactionStatusError, chunks[0].Status)
+	assert.Equal(t, "test fatal", chunks[0].StatusMessage)
+	assert.Equal(t, LevelFatal, chunks[0].SubTransactions[0].TransactionLogs[0].LoggerLevel)
+	assert.Empty(t, OpenTransactions())
+}
+
+func TestTransactionPanicInRunInTransaction(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var chunks []*TransactionLoggerData
+
+	err := RunInTransaction(testTransactionId, func(tx *TransactionLogger) error {
+		tx.Panic("test panic", MetaData{"id": 1})
+		return nil
+	}, WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&chunks)))
+
+	assert.Error(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, TransactionStatusError, chunks[0].Status)
+	assert.Equal(t, LevelPanic, chunks[0].TransactionLogs[0].LoggerLevel)
+	assert.Equal(t, "test panic", chunks[0].TransactionLogs[0].Message)
+}
@@ -0,0 +1,569 @@
+package logging
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"go-telemetry/pkg/internal/config"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// rotated file name timestamp format, without characters that are invalid in file names
+	rotationTimestampFormat = "2006-01-02T15-04-05.000"
+
+	compressedFileExtension = ".gz"
+
+	fileBufferSize    = 64 * 1024
+	fileFlushInterval = time.Second
+
+	bytesInMegabyte = 1024 * 1024
+	hoursInDay      = 24 * time.Hour
+)
+
+// A FileRotation holds the rotation and retention policy of the log files.
+// The zero value never rotates nor deletes files.
+type FileRotation struct {
+	MaxSize    int64         // the size in bytes that triggers the rotation of the file, 0 disables size based rotation
+	Interval   time.Duration // the age that triggers the rotation of the file, 0 disables time based rotation
+	Compress   bool          // gzip the rotated files
+	MaxBackups int           // the number of rotated files to keep, 0 keeps all of them
+	MaxAge     time.Duration // the age after which rotated files are deleted, 0 keeps them forever
+}
+
+// A fileSink is the long-lived output of a file writer, that keeps the active file open between writes.
+// The active file is named by the current day, e.g. 2006-01-02.log, and is rotated, compressed and cleaned up by the rotation policy.
+//
+// The appended data is buffered and written to the file when the buffer is full, after the flush interval, or when the sink is flushed or closed.
+// A closed sink opens the active file again on the next write.
+//
+// The rotated files are compressed in the background, the retention policy being applied once they are compressed.
+type fileSink struct {
+	mutex         sync.Mutex
+	dir           string
+	suffix        string
+	flag          int
+	rotation      FileRotation
+	backupPattern *regexp.Regexp
+
+	activeName  string
+	activeSince time.Time
+	file        *os.File
+	buffer      *bufio.Writer
+	size        int64
+	flushTimer  *time.Timer
+
+	compressing map[string]bool // the rotated files being compressed in the background
+	compressed  *sync.Cond      // signaled, using the mutex of the sink, when a rotated file is compressed
+}
+
+// A fileSinkKey identifies the files written by a sink: the file writers with the same key share a single sink.
+type fileSinkKey struct {
+	dir    string
+	suffix string
+	flag   int
+}
+
+var fileSinks sync.Map // fileSinkKey -> *fileSink, the sinks of every file writer
+
+var openFileSinks sync.Map // *fileSink -> struct{}, the sinks that hold an open file
+
+// newFileSink returns the file sink for the files with the given suffix (e.g. ".log", "_transactions.json"), opened using the given flags,
+// respective to the defined YAML configuration or by given "drivers" in form of options argument.
+//
+// The file writers of the same files share a single sink, so that the files are opened, buffered and rotated once.
+// The rotation policy of the first writer applies to the shared sink: a warning is printed if a later writer defines a different one,
+// so that a writer never changes the retention of the files of another logger.
+func newFileSink(suffix string, flag int, options ...func(*fileSink)) *fileSink {
+	config.Init()
+
+	s := &fileSink{
+		dir:      config.LoggerConfig.Logger.OutputDir,
+		suffix:   suffix,
+		flag:     flag,
+		rotation: fileRotationFromConfig(config.LoggerConfig.Logger),
+
+		compressing: map[string]bool{},
+	}
+	s.compressed = sync.NewCond(&s.mutex)
+
+	// File sink options override the YAML file configuration
+	for _, o := range options {
+		o(s)
+	}
+	s.backupPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(\.[0-9T.-]+)?` + regexp.QuoteMeta(s.suffix) + `(` + regexp.QuoteMeta(compressedFileExtension) + `)?$`)
+
+	dir, err := filepath.Abs(s.dir)
+	if err != nil {
+		dir = filepath.Clean(s.dir)
+	}
+	existing, loaded := fileSinks.LoadOrStore(fileSinkKey{dir: dir, suffix: s.suffix, flag: s.flag}, s)
+	if !loaded {
+		return s
+	}
+
+	shared := existing.(*fileSink)
+	shared.mutex.Lock()
+	defer shared.mutex.Unlock()
+	if shared.rotation != s.rotation {
+		fmt.Printf("warning: the %s files of %s are already written using another rotation policy, the rotation policy of the first writer is kept\n", s.suffix, dir)
+	}
+	return shared
+}
+
+// WithFileRotation is a pre-defined "driver" that specifies the rotation and retention policy of a file output writer
+func WithFileRotation(rotation FileRotation) func(*fileSink) {
+	return func(s *fileSink) {
+		s.rotation = rotation
+	}
+}
+
+// WithFileOutputDir is a pre-defined "driver" that specifies the directory where a file output writer saves its files
+func WithFileOutputDir(dir string) func(*fileSink) {
+	return func(s *fileSink) {
+		s.dir = dir
+	}
+}
+
+// WithFileSuffix is a pre-defined "driver" that specifies the suffix appended to the date in the names of the files of a file output writer (e.g. "_orders.jsonl")
+func WithFileSuffix(suffix string) func(*fileSink) {
+	return func(s *fileSink) {
+		s.suffix = suffix
+	}
+}
+
+// fileRotationFromConfig converts the YAML rotation configuration to a rotation policy.
+// An invalid rotation interval disables time based rotation, the invalid intervals of the YAML file being reported once, when the configuration is loaded.
+func fileRotationFromConfig(loggerConfig config.Logger) FileRotation {
+	rotation := FileRotation{
+		MaxSize:    int64(loggerConfig.MaxSizeMB) * bytesInMegabyte,
+		Compress:   loggerConfig.Compress,
+		MaxBackups: loggerConfig.MaxBackups,
+		MaxAge:     time.Duration(loggerConfig.MaxAgeDays) * hoursInDay,
+	}
+	interval, err := time.ParseDuration(loggerConfig.RotationInterval)
+	if err == nil {
+		rotation.Interval = interval
+	}
+	return rotation
+}
+
+// write hands the active file to the write function, for the writers that modify the file in place (e.g. the JSON array writers).
+//
+// write is safe to call concurrently with other operations and will
+// block until all other operations finish.
+func (s *fileSink) write(writeFile func(f *os.File) error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.prepare()
+	if err != nil {
+		return err
+	}
+	defer lockFile(s.activeName)()
+
+	err = s.buffer.Flush()
+	if err != nil {
+		return fmt.Errorf("error: could not write file %v", err)
+	}
+
+	err = writeFile(s.file)
+	if info, statErr := s.file.Stat(); statErr == nil {
+		s.size = info.Size()
+	}
+	return err
+}
+
+// append adds the data at the end of the active file, using the buffer.
+// The data is never split between two writes to the file, so that the records of sinks sharing a file do not interleave.
+//
+// append is safe to call concurrently with other operations and will
+// block until all other operations finish.
+func (s *fileSink) append(data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.prepare()
+	if err != nil {
+		return err
+	}
+	defer lockFile(s.activeName)()
+
+	if s.buffer.Available() < len(data) {
+		err = s.buffer.Flush()
+		if err != nil {
+			return fmt.Errorf("error: could not write file %v", err)
+		}
+	}
+	_, err = s.buffer.Write(data)
+	if err != nil {
+		return fmt.Errorf("error: could not write file %v", err)
+	}
+	s.size += int64(len(data))
+
+	if s.buffer.Buffered() > 0 && s.flushTimer == nil {
+		s.flushTimer = time.AfterFunc(fileFlushInterval, func() {
+			err := s.flush()
+			if err != nil {
+				fmt.Println(err)
+			}
+		})
+	}
+	return nil
+}
+
+// prepare makes sure the active file of the current day is open, rotating it first if the rotation policy requires it.
+func (s *fileSink) prepare() error {
+	now := time.Now()
+	fileName := filepath.Join(s.dir, now.Format(fileTimestampFormat)+s.suffix)
+
+	if fileName != s.activeName {
+		// on a new day, the files of the previous days become backups
+		err := s.closeFile()
+		if err != nil {
+			fmt.Println(err)
+		}
+		s.activeName = fileName
+		s.activeSince = now
+		err = s.removeExpiredBackups(now)
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	if s.file == nil {
+		err := s.openFile()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := s.rotateIfNeeded(now)
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	if s.file == nil {
+		return s.openFile()
+	}
+	return nil
+}
+
+// openFile opens the active file and registers the sink as open.
+func (s *fileSink) openFile() error {
+	f, err := os.OpenFile(s.activeName, s.flag, 0644)
+	if err != nil {
+		return fmt.Errorf("error: could not open file %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error: could not open file %v", err)
+	}
+
+	s.file = f
+	s.size = info.Size()
+	if s.buffer == nil {
+		s.buffer = bufio.NewWriterSize(f, fileBufferSize)
+	} else {
+		s.buffer.Reset(f)
+	}
+	openFileSinks.Store(s, struct{}{})
+	return nil
+}
+
+// closeFile writes the buffered data and closes the active file, if it is open.
+func (s *fileSink) closeFile() error {
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
+	if s.file == nil {
+		return nil
+	}
+
+	unlock := lockFile(s.activeName)
+	flushErr := s.buffer.Flush()
+	unlock()
+	closeErr := s.file.Close()
+
+	s.file = nil
+	openFileSinks.Delete(s)
+
+	if flushErr != nil {
+		return fmt.Errorf("error: could not write file %v", flushErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("error: could not close file %v", closeErr)
+	}
+	return nil
+}
+
+// flush writes the buffered data to the active file.
+//
+// flush is safe to call concurrently with other operations and will
+// block until all other operations finish.
+func (s *fileSink) flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
+	if s.file == nil {
+		return nil
+	}
+
+	defer lockFile(s.activeName)()
+	err := s.buffer.Flush()
+	if err != nil {
+		return fmt.Errorf("error: could not write file %v", err)
+	}
+	return nil
+}
+
+// close writes the buffered data and closes the active file, then waits for the rotated files to be compressed.
+// The file is opened again on the next write.
+//
+// close is safe to call concurrently with other operations and will
+// block until all other operations finish.
+func (s *fileSink) close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.closeFile()
+	for len(s.compressing) > 0 {
+		s.compressed.Wait()
+	}
+	return err
+}
+
+// rotateIfNeeded rotates the active file when it reached the maximum size or age, then applies the retention policy.
+// The active file is closed when it is rotated.
+func (s *fileSink) rotateIfNeeded(now time.Time) error {
+	if s.size == 0 {
+		return nil
+	}
+
+	sizeExceeded := s.rotation.MaxSize > 0 && s.size >= s.rotation.MaxSize
+	intervalExceeded := s.rotation.Interval > 0 && now.Sub(s.activeSince) >= s.rotation.Interval
+	if !sizeExceeded && !intervalExceeded {
+		return nil
+	}
+
+	err := s.closeFile()
+	if err != nil {
+		return err
+	}
+	rotatedName, err := s.rotate(now)
+	s.activeSince = now
+	if err != nil {
+		return err
+	}
+	if s.rotation.Compress {
+		s.compressInBackground(rotatedName)
+		return nil
+	}
+	return s.removeExpiredBackups(now)
+}
+
+// rotate renames the active file using the rotation timestamp and returns the name of the rotated file.
+// A counter is appended to the timestamp when a backup with the same name already exists, so that a backup is never overwritten.
+func (s *fileSink) rotate(now time.Time) (string, error) {
+	base := filepath.Base(s.activeName)
+	rotatedBase := filepath.Join(s.dir, base[:len(base)-len(s.suffix)]+"."+now.Format(rotationTimestampFormat))
+	rotatedName := rotatedBase + s.suffix
+	for i := 1; fileExists(rotatedName) || fileExists(rotatedName+compressedFileExtension); i++ {
+		rotatedName = rotatedBase + "-" + strconv.Itoa(i) + s.suffix
+	}
+
+	err := os.Rename(s.activeName, rotatedName)
+	if err != nil {
+		return "", fmt.Errorf("error: could not rotate file %v", err)
+	}
+	return rotatedName, nil
+}
+
+// compressInBackground compresses the rotated file without holding the lock of the sink, so that the writes are not blocked meanwhile,
+// then applies the retention policy.
+//
+// compressInBackground must be called while holding the lock of the sink.
+func (s *fileSink) compressInBackground(rotatedName string) {
+	s.compressing[rotatedName] = true
+	go func() {
+		err := compressFile(rotatedName)
+		if err != nil {
+			fmt.Println(err)
+		}
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		delete(s.compressing, rotatedName)
+		s.compressed.Broadcast()
+		err = s.removeExpiredBackups(time.Now())
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+}
+
+// removeExpiredBackups deletes the rotated files (and the files of previous days) that exceed the maximum number of backups or age.
+func (s *fileSink) removeExpiredBackups(now time.Time) error {
+	if s.rotation.MaxBackups <= 0 && s.rotation.MaxAge <= 0 {
+		return nil
+	}
+
+	dir := s.dir
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error: could not read the output directory %v", err)
+	}
+
+	type backup struct {
+		name    string
+		modTime time.Time
+	}
+	var backups []backup
+	for _, entry := range entries {
+		name := filepath.Join(s.dir, entry.Name())
+		if entry.IsDir() || name == s.activeName || s.compressing[name] || !s.backupPattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{name: name, modTime: info.ModTime()})
+	}
+
+	// newest backups first
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+
+	for i, b := range backups {
+		tooMany := s.rotation.MaxBackups > 0 && i >= s.rotation.MaxBackups
+		tooOld := s.rotation.MaxAge > 0 && now.Sub(b.modTime) > s.rotation.MaxAge
+		if tooMany || tooOld {
+			err = os.Remove(b.name)
+			if err != nil {
+				return fmt.Errorf("error: could not remove rotated file %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// fileExists reports if the given file exists.
+func fileExists(fileName string) bool {
+	_, err := os.Lstat(fileName)
+	return err == nil
+}
+
+// compressFile gzips the given file and removes the uncompressed one.
+func compressFile(fileName string) error {
+	src, err := os.Open(fileName)
+	if err != nil {
+		return fmt.Errorf("error: could not open rotated file %v", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(fileName+compressedFileExtension, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("error: could not create compressed file %v", err)
+	}
+	defer dst.Close()
+
+	gzipWriter := gzip.NewWriter(dst)
+	_, err = io.Copy(gzipWriter, src)
+	if err != nil {
+		return fmt.Errorf("error: could not compress rotated file %v", err)
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return fmt.Errorf("error: could not compress rotated file %v", err)
+	}
+
+	src.Close()
+	err = os.Remove(fileName)
+	if err != nil {
+		return fmt.Errorf("error: could not remove rotated file %v", err)
+	}
+	return nil
+}
+
+// FlushFileSinks writes the buffered data of every file writer to its file.
+func FlushFileSinks() error {
+	var errs []error
+	openFileSinks.Range(func(key, _ any) bool {
+		errs = append(errs, key.(*fileSink).flush())
+		return true
+	})
+	return errors.Join(errs...)
+}
+
+// CloseFileSinks writes the buffered data and closes the file of every file writer, e.g. on graceful shutdown.
+// The file writers open their file again on the next write.
+func CloseFileSinks() error {
+	var errs []error
+	openFileSinks.Range(func(key, _ any) bool {
+		errs = append(errs, key.(*fileSink).close())
+		return true
+	})
+	return errors.Join(errs...)
+}
+
+// ReopenFileSinks closes the file of every file writer, so that the next write opens the file again,
+// e.g. after the files were moved by an external tool like logrotate.
+func ReopenFileSinks() error {
+	return CloseFileSinks()
+}
+
+// ReopenFileSinksOnSignal reopens the files of the file writers every time the process receives one of the given signals.
+// If no signal is given, SIGHUP is used, as sent by external tools like logrotate.
+//
+// The returned function stops the signal handling.
+func ReopenFileSinksOnSignal(signals ...os.Signal) func() {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	signalChannel := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signalChannel, signals...)
+	go func() {
+		for {
+			select {
+			case <-signalChannel:
+				err := ReopenFileSinks()
+				if err != nil {
+					fmt.Println(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	return func() {
+		stopOnce.Do(func() {
+			signal.Stop(signalChannel)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package logging
+
+import (
+	"compress/gzip"
+	"go-telemetry/pkg/internal/config"
+	itesting "go-telemetry/pkg/internal/telemetrytesting"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fileSinkTestDirName = "testfilesink"
+
+func setupFileSinkTestEnvironment(t *testing.T) {
+	setupTestEnvironment(t, fileSinkTestDirName)
+	outputDir := config.LoggerConfig.Logger.OutputDir
+	t.Cleanup(func() {
+		err := CloseFileSinks()
+		if err != nil {
+			t.Errorf("error: could not close the file sinks %v", err)
+		}
+		forgetFileSinks()
+		err = os.RemoveAll(outputDir)
+		if err != nil {
+			t.Errorf("error: could not delete test artifacts %v", err)
+		}
+	})
+}
+
+// listFiles returns the names of the files in the test output directory
+func listFiles(t *testing.T) []string {
+	entries, err := os.ReadDir(config.LoggerConfig.Logger.OutputDir)
+	if err != nil {
+		t.Fatalf("fatal: could not read the test directory %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestWithFileOutputDir(t *testing.T) {
+	s := fileSink{}
+	WithFileOutputDir("logs")(&s)
+	assert.Equal(t, "logs", s.dir)
+}
+
+func TestWithFileSuffix(t *testing.T) {
+	s := newFileSink(".log", os.O_WRONLY|os.O_CREATE|os.O_APPEND, WithFileSuffix("_orders.jsonl"))
+	assert.Equal(t, "_orders.jsonl", s.suffix)
+	assert.True(t, s.backupPattern.MatchString("2024-01-02.150405_orders.jsonl"))
+	assert.False(t, s.backupPattern.MatchString("2024-01-02.log"))
+}
+
+func TestFileRotationFromConfig(t *testing.T) {
+	rotation := fileRotationFromConfig(config.Logger{
+		MaxSizeMB:        2,
+		RotationInterval: "1h",
+		Compress:         true,
+		MaxBackups:       3,
+		MaxAgeDays:       7,
+	})
+
+	assert.Equal(t, FileRotation{
+		MaxSize:    2 * 1024 * 1024,
+		Interval:   time.Hour,
+		Compress:   true,
+		MaxBackups: 3,
+		MaxAge:     7 * 24 * time.Hour,
+	}, rotation)
+}
+
+func TestFileRotationFromConfigWithInvalidInterval(t *testing.T) {
+	rotation := fileRotationFromConfig(config.Logger{
+		RotationInterval: "undefined",
+	})
+
+	assert.Equal(t, time.Duration(0), rotation.Interval)
+}
+
+func TestFileSinkSizeRotation(t *testing.T) {
+	setupFileSinkTestEnvironment(t)
+
+	writer := TextLogOutputFileWrite(WithFileRotation(FileRotation{MaxSize: 1}))
+	for range 2 {
+		err := writer(&testLogging)
+		if err != nil {
+			t.Fatalf("fatal: could not write logs to text file %v", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	err := FlushFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not flush the file sinks %v", err)
+	}
+
+	activeName := time.Now().Format(fileTimestampFormat) + ".log"
+	files := listFiles(t)
+	assert.Len(t, files, 2)
+	assert.Contains(t, files, activeName)
+
+	b, err := os.ReadFile(filepath.Join(config.LoggerConfig.Logger.OutputDir, activeName))
+	if err != nil {
+		t.Fatalf("error: could not read from text file %v", err)
+	}
+	assert.Equal(t, 1, strings.Count(string(b), "\n"))
+}
+
+func TestFileSinkIntervalRotationWithCompression(t *testing.T) {
+	setupFileSinkTestEnvironment(t)
+
+	writer := TextLogOutputFileWrite(WithFileRotation(FileRotation{Interval: time.Millisecond, Compress: true}))
+	for range 2 {
+		err := writer(&testLogging)
+		if err != nil {
+			t.Fatalf("fatal: could not write logs to text file %v", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	// closing the sinks waits for the rotated files to be compressed
+	err := CloseFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not close the file sinks %v", err)
+	}
+
+	var compressedName string
+	for _, name := range listFiles(t) {
+		if strings.HasSuffix(name, ".log.gz") {
+			compressedName = name
+		}
+	}
+	if compressedName == "" {
+		t.Fatalf("fatal: the rotated file was not compressed")
+	}
+
+	f, err := os.Open(filepath.Join(config.LoggerConfig.Logger.OutputDir, compressedName))
+	if err != nil {
+		t.Fatalf("error: could not open compressed file %v", err)
+	}
+	defer f.Close()
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("error: could not read compressed file %v", err)
+	}
+	b, err := io.ReadAll(gzipReader)
+	if err != nil {
+		t.Fatalf("error: could not read compressed file %v", err)
+	}
+
+	assert.Contains(t, string(b), logFormat(testLogging))
+}
+
+func TestFileSinkSharedBetweenWriters(t *testing.T) {
+	setupFileSinkTestEnvironment(t)
+
+	rotation := FileRotation{MaxSize: bytesInMegabyte, MaxBackups: 30}
+	textSink := newFileSink(".log", os.O_WRONLY|os.O_CREATE|os.O_APPEND, WithFileRotation(rotation))
+	var sharedSink *fileSink
+	output, err := itesting.CaptureOutput(func() error {
+		sharedSink = newFileSink(".log", os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("fatal: could not capture stdout output %v", err)
+	}
+	jsonSink := newFileSink(".json", os.O_WRONLY|os.O_CREATE)
+
+	assert.Same(t, textSink, sharedSink)
+	assert.Equal(t, rotation, sharedSink.rotation)
+	assert.Contains(t, output, "warning: the .log files of")
+	assert.NotSame(t, textSink, jsonSink)
+}
+
+func TestFileSinkSharedRotationWithCompression(t *testing.T) {
+	setupFileSinkTestEnvironment(t)
+
+	rotation := WithFileRotation(FileRotation{MaxSize: 2000, Compress: true})
+	writers := []LogOutputWriter{TextLogOutputFileWrite(rotation), TextLogOutputFileWrite(rotation)}
+	for range 200 {
+		for _, writer := range writers {
+			err := writer(&testLogging)
+			if err != nil {
+				t.Fatalf("fatal: could not write logs to text file %v", err)
+			}
+		}
+	}
+	// closing the sinks waits for the rotated files to be compressed
+	err := CloseFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not close the file sinks %v", err)
+	}
+
+	lines := 0
+	for _, name := range listFiles(t) {
+		f, err := os.Open(filepath.Join(config.LoggerConfig.Logger.OutputDir, name))
+		if err != nil {
+			t.Fatalf("error: could not open log file %v", err)
+		}
+		var reader io.Reader = f
+		if strings.HasSuffix(name, compressedFileExtension) {
+			reader, err = gzip.NewReader(f)
+			if err != nil {
+				t.Fatalf("error: could not read compressed file %v", err)
+			}
+		}
+		b, err := io.ReadAll(reader)
+		f.Close()
+		if err != nil {
+			t.Fatalf("error: could not read log file %v", err)
+		}
+		lines += strings.Count(string(b), "\n")
+	}
+
+	assert.Equal(t, 400, lines)
+}
+
+func TestFileSinkRotationKeepsBackupsWithSameTimestamp(t *testing.T) {
+	setupFileSinkTestEnvironment(t)
+
+	sink := newFileSink(".log", os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+	now := time.Now()
+	for _, data := range []string{"test1\n", "test2\n"} {
+		err := sink.append([]byte(data))
+		if err != nil {
+			t.Fatalf("fatal: could not append to the file sink %v", err)
+		}
+		err = sink.close()
+		if err != nil {
+			t.Fatalf("fatal: could not close the file sink %v", err)
+		}
+		_, err = sink.rotate(now)
+		if err != nil {
+			t.Fatalf("fatal: could not rotate the file sink %v", err)
+		}
+	}
+
+	prefix := now.Format(fileTimestampFormat) + "." + now.Format(rotationTimestampFormat)
+	files := listFiles(t)
+	assert.ElementsMatch(t, []string{prefix + ".log", prefix + "-1.log"}, files)
+	for _, name := range files {
+		assert.True(t, sink.backupPattern.MatchString(name))
+	}
+}
+
+func TestFileSinkMaxBackups(t *testing.T) {
+	setupFileSinkTestEnvironment(t)
+
+	writer := JSONLinesLogOutputFileWrite(WithFileRotation(FileRotation{MaxSize: 1, MaxBackups: 1}))
+	for range 4 {
+		err := writer(&testLogging)
+		if err != nil {
+			t.Fatalf("fatal: could not write logs to json lines file %v", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// the active file and a single backup
+	assert.Len(t, listFiles(t), 2)
+}
+
+func TestFileSinkMaxAge(t *testing.T) {
+	setupFileSinkTestEnvironment(t)
+
+	expiredName := filepath.Join(config.LoggerConfig.Logger.OutputDir, "2000-01-01.log")
+	unrelatedName := filepath.Join(config.LoggerConfig.Logger.OutputDir, "2000-01-01.json")
+	for _, name := range []string{expiredName, unrelatedName} {
+		err := os.WriteFile(name, []byte("test\n"), 0644)
+		if err != nil {
+			t.Fatalf("fatal: could not write test file %v", err)
+		}
+		err = os.Chtimes(name, time.Time{}, time.Now().Add(-48*time.Hour))
+		if err != nil {
+			t.Fatalf("fatal: could not change test file time %v", err)
+		}
+	}
+
+	err := TextLogOutputFileWrite(WithFileRotation(FileRotation{MaxAge: 24 * time.Hour}))(&testLogging)
+	if err != nil {
+		t.Fatalf("fatal: could not write logs to text file %v", err)
+	}
+
+	files := listFiles(t)
+	assert.NotContains(t, files, "2000-01-01.log")
+	assert.Contains(t, files, "2000-01-01.json")
+}
+
+func TestFileSinkKeepsFileOpen(t *testing.T) {
+	setupFileSinkTestEnvironment(t)
+
+	sink := newFileSink(".log", os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+	err := sink.append([]byte("test1\n"))
+	if err != nil {
+		t.Fatalf("fatal: could not append to the file sink %v", err)
+	}
+	openFile := sink.file
+	err = sink.append([]byte("test2\n"))
+	if err != nil {
+		t.Fatalf("fatal: could not append to the file sink %v", err)
+	}
+
+	assert.Same(t, openFile, sink.file)
+	assert.Equal(t, 12, sink.buffer.Buffered())
+	_, found := openFileSinks.Load(sink)
+	assert.True(t, found)
+
+	err = sink.close()
+	if err != nil {
+		t.Fatalf("fatal: could not close the file sink %v", err)
+	}
+
+	_, found = openFileSinks.Load(sink)
+	assert.False(t, found)
+	assert.Nil(t, sink.file)
+	b, err := os.ReadFile(sink.activeName)
+	if err != nil {
+		t.Fatalf("error: could not read from text file %v", err)
+	}
+	assert.Equal(t, "test1\ntest2\n", string(b))
+}
+
+func TestReopenFileSinks(t *testing.T) {
+	setupFileSinkTestEnvironment(t)
+
+	sink := newFileSink(".log", os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+	err := sink.append([]byte("test1\n"))
+	if err != nil {
+		t.Fatalf("fatal: could not append to the file sink %v", err)
+	}
+
+	// an external tool moves the active file
+	movedName := sink.activeName + ".1"
+	err = os.Rename(sink.activeName, movedName)
+	if err != nil {
+		t.Fatalf("fatal: could not move the active file %v", err)
+	}
+	err = ReopenFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not reopen the file sinks %v", err)
+	}
+	err = sink.append([]byte("test2\n"))
+	if err != nil {
+		t.Fatalf("fatal: could not append to the file sink %v", err)
+	}
+	err = sink.flush()
+	if err != nil {
+		t.Fatalf("fatal: could not flush the file sink %v", err)
+	}
+
+	moved, err := os.ReadFile(movedName)
+	if err != nil {
+		t.Fatalf("error: could not read from moved file %v", err)
+	}
+	active, err := os.ReadFile(sink.activeName)
+	if err != nil {
+		t.Fatalf("error: could not read from active file %v", err)
+	}
+	assert.Equal(t, "test1\n", string(moved))
+	assert.Equal(t, "test2\n", string(active))
+}
+
+func TestReopenFileSinksOnSignal(t *testing.T) {
+	setupFileSinkTestEnvironment(t)
+
+	stop := ReopenFileSinksOnSignal(syscall.SIGHUP)
+	t.Cleanup(stop)
+
+	sink := newFileSink(".log", os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+	err := sink.append([]byte("test\n"))
+	if err != nil {
+		t.Fatalf("fatal: could not append to the file sink %v", err)
+	}
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("fatal: could not find the current process %v", err)
+	}
+	err = process.Signal(syscall.SIGHUP)
+	if err != nil {
+		t.Skipf("skip: signals are not supported %v", err)
+	}
+
+	assert.Eventually(t, func() bool {
+		_, found := openFileSinks.Load(sink)
+		return !found
+	}, time.Second, 10*time.Millisecond)
+}
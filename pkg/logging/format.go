@@ -0,0 +1,344 @@
+package logging
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	nilValue      = "<nil>"
+	cycleValue    = "<cycle>"
+	maxDepthValue = "<max depth>"
+)
+
+// maxValueDepth is the maximum nesting of the values rendered by formatValue, deeper values are replaced by maxDepthValue.
+const maxValueDepth = 32
+
+// formatLoggerData renders a log as a single text line, without the line ending.
+func formatLoggerData(loggerData *LoggerData) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("[%s] [%s] %s", loggerData.Timestamp.Format(timestampFormat), loggerData.LoggerLevel, loggerData.Message))
+	for _, k := range loggerData.MetaDataKeys() {
+		sb.WriteString(fmt.Sprintf(" [%s=%s]", k, formatValue(loggerData.MetaData[k])))
+	}
+	for _, field := range callerFields(loggerData.Caller) {
+		sb.WriteString(fmt.Sprintf(" [%s=%s]", field.Key, formatValue(field.Value)))
+	}
+	return sb.String()
+}
+
+// formatTransactionLoggerData renders a transaction as text lines, each log being prefixed by an arrow.
+// The sub-transactions are rendered between their own start and end lines, with a longer arrow for every nesting level.
+func formatTransactionLoggerData(transactionId string, startTimestamp time.Time, endTimestamp time.Time, transactionLoggerData *TransactionLoggerData) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("[%s] Transaction {%s} started!\n", startTimestamp.Format(timestampFormat), transactionId))
+	writeTransactionEntries(&sb, transactionLoggerData, "--> ")
+	sb.WriteString(fmt.Sprintf("[%s] Transaction {%s} ended!%s\n", endTimestamp.Format(timestampFormat), transactionId, formatTransactionOutcome(transactionLoggerData, endTimestamp.Sub(startTimestamp))))
+	return sb.String()
+}
+
+// writeTransactionEntries renders the logs and the sub-transactions of a transaction as text lines, each line being prefixed by the arrow.
+// The sub-transactions that go on in the next chunks of a partial transaction have no end line.
+func writeTransactionEntries(sb *strings.Builder, transactionLoggerData *TransactionLoggerData, arrow string) {
+	walkTransactionLoggerData(transactionLoggerData, func(entry *LoggerData) {
+		sb.WriteString(arrow + formatLoggerData(entry) + "\n")
+	}, func(subTransaction *SubTransactionLoggerData) {
+		sb.WriteString(fmt.Sprintf("%s[%s] Sub-transaction {%s} started!\n", arrow, subTransaction.StartTimestamp.Format(timestampFormat), subTransaction.TransactionID))
+		writeTransactionEntries(sb, &subTransaction.TransactionLoggerData, "--"+arrow)
+		if subTransaction.EndTimestamp.IsZero() {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("%s[%s] Sub-transaction {%s} ended!%s\n", arrow, subTransaction.EndTimestamp.Format(timestampFormat), subTransaction.TransactionID,
+			formatTransactionOutcome(&subTransaction.TransactionLoggerData, subTransaction.EndTimestamp.Sub(subTransaction.StartTimestamp))))
+	})
+}
+
+// formatTransactionOutcome renders the status, the status message and the duration of a transaction as text attributes.
+// The status and its message are rendered only if they are set.
+func formatTransactionOutcome(transactionLoggerData *TransactionLoggerData, duration time.Duration) string {
+	var sb strings.Builder
+	for _, field := range transactionOutcomeFields(transactionLoggerData, duration) {
+		sb.WriteString(fmt.Sprintf(" [%s=%s]", field.Key, formatValue(field.Value)))
+	}
+	return sb.String()
+}
+
+// transactionOutcomeFields returns the status, the status message, the number of dropped logs, the partial marker and the duration of a transaction,
+// omitting the ones that are not set.
+func transactionOutcomeFields(transactionLoggerData *TransactionLoggerData, duration time.Duration) []Field {
+	var fields []Field
+	if transactionLoggerData.Status != "" {
+		fields = append(fields, Field{Key: "status", Value: transactionLoggerData.Status})
+	}
+	if transactionLoggerData.StatusMessage != "" {
+		fields = append(fields, Field{Key: "statusMessage", Value: transactionLoggerData.StatusMessage})
+	}
+	if transactionLoggerData.DroppedLogs > 0 {
+		fields = append(fields, Field{Key: "droppedLogs", Value: transactionLoggerData.DroppedLogs})
+	}
+	if transactionLoggerData.Partial {
+		fields = append(fields, Field{Key: "partial", Value: true})
+	}
+	return append(fields, Field{Key: "duration", Value: duration})
+}
+
+// walkTransactionLoggerData visits the logs and the sub-transactions of a transaction in chronological order,
+// a sub-transaction being visited before the logs registered after it started.
+func walkTransactionLoggerData(transactionLoggerData *TransactionLoggerData, visitLog func(*LoggerData), visitSubTransaction func(*SubTransactionLoggerData)) {
+	logs := transactionLoggerData.TransactionLogs
+	subTransactions := transactionLoggerData.SubTransactions
+	for len(logs) > 0 || len(subTransactions) > 0 {
+		if len(subTransactions) == 0 || (len(logs) > 0 && !logs[0].Timestamp.After(subTransactions[0].StartTimestamp)) {
+			visitLog(logs[0])
+			logs = logs[1:]
+		} else {
+			visitSubTransaction(subTransactions[0])
+			subTransactions = subTransactions[1:]
+		}
+	}
+}
+
+// formatLogfmtLoggerData renders a log as a single logfmt line (ts=... level=... msg=... key=value), without the line ending.
+// The additional fields (e.g. the transaction id) are written before the message.
+func formatLogfmtLoggerData(loggerData *LoggerData, fields ...Field) string {
+	var sb strings.Builder
+	writeLogfmtPair(&sb, "ts", loggerData.Timestamp)
+	writeLogfmtPair(&sb, "level", loggerData.LoggerLevel)
+	for _, field := range fields {
+		writeLogfmtPair(&sb, field.Key, field.Value)
+	}
+	writeLogfmtPair(&sb, "msg", loggerData.Message)
+	for _, k := range loggerData.MetaDataKeys() {
+		writeLogfmtPair(&sb, k, loggerData.MetaData[k])
+	}
+	for _, field := range callerFields(loggerData.Caller) {
+		writeLogfmtPair(&sb, field.Key, field.Value)
+	}
+	return sb.String()
+}
+
+// formatLogfmtTransactionLoggerData renders a transaction as logfmt lines, each line holding the transaction id.
+// The lines of the sub-transactions also hold the sub-transaction id, as a path of the nested sub-transaction ids (e.g. "payment/charge card").
+func formatLogfmtTransactionLoggerData(transactionId string, startTimestamp time.Time, endTimestamp time.Time, transactionLoggerData *TransactionLoggerData) string {
+	var sb strings.Builder
+	transactionIdField := Field{Key: "transactionId", Value: transactionId}
+	sb.WriteString(formatLogfmtLoggerData(&LoggerData{LoggerLevel: transactionLoggerData.LoggerLevel, Timestamp: startTimestamp, Message: "transaction started"}, transactionIdField) + "\n")
+	writeLogfmtTransactionEntries(&sb, transactionLoggerData, transactionIdField)
+	sb.WriteString(formatLogfmtLoggerData(transactionOutcomeLoggerData(transactionLoggerData, endTimestamp, "transaction ended", endTimestamp.Sub(startTimestamp)), transactionIdField) + "\n")
+	return sb.String()
+}
+
+// writeLogfmtTransactionEntries renders the logs and the sub-transactions of a transaction as logfmt lines, each line holding the given fields.
+// The sub-transactions that go on in the next chunks of a partial transaction have no end line.
+func writeLogfmtTransactionEntries(sb *strings.Builder, transactionLoggerData *TransactionLoggerData, fields ...Field) {
+	walkTransactionLoggerData(transactionLoggerData, func(entry *LoggerData) {
+		sb.WriteString(formatLogfmtLoggerData(entry, fields...) + "\n")
+	}, func(subTransaction *SubTransactionLoggerData) {
+		subTransactionId := subTransaction.TransactionID
+		if len(fields) > 1 {
+			subTransactionId = fmt.Sprintf("%s/%s", fields[1].Value, subTransactionId)
+		}
+		subFields := []Field{fields[0], {Key: "subTransactionId", Value: subTransactionId}}
+		sb.WriteString(formatLogfmtLoggerData(&LoggerData{LoggerLevel: subTransaction.LoggerLevel, Timestamp: subTransaction.StartTimestamp, Message: "sub-transaction started"}, subFields...) + "\n")
+		writeLogfmtTransactionEntries(sb, &subTransaction.TransactionLoggerData, subFields...)
+		if subTransaction.EndTimestamp.IsZero() {
+			return
+		}
+		sb.WriteString(formatLogfmtLoggerData(transactionOutcomeLoggerData(&subTransaction.TransactionLoggerData, subTransaction.EndTimestamp, "sub-transaction ended",
+			subTransaction.EndTimestamp.Sub(subTransaction.StartTimestamp)), subFields...) + "\n")
+	})
+}
+
+// transactionOutcomeLoggerData returns the log that ends a transaction, holding its outcome as attributes.
+func transactionOutcomeLoggerData(transactionLoggerData *TransactionLoggerData, endTimestamp time.Time, msg string, duration time.Duration) *LoggerData {
+	metaData, metaDataOrder := fieldsToMetaData(transactionOutcomeFields(transactionLoggerData, duration))
+	return &LoggerData{LoggerLevel: transactionLoggerData.LoggerLevel, Timestamp: endTimestamp, Message: msg, MetaData: metaData, MetaDataOrder: metaDataOrder}
+}
+
+// writeLogfmtPair appends a key=value pair to a logfmt line, an Err attribute being appended as several pairs (see writeLogfmtErrorValue).
+func writeLogfmtPair(sb *strings.Builder, key string, value any) {
+	if errorValue, ok := value.(*ErrorValue); ok && errorValue != nil {
+		writeLogfmtErrorValue(sb, key, errorValue)
+		return
+	}
+	if sb.Len() > 0 {
+		sb.WriteByte(' ')
+	}
+	formatted, _ := formatRawValue(value)
+	sb.WriteString(logfmtKey(key) + "=" + quoteLogfmtIfNeeded(formatted))
+}
+
+// logfmtKey replaces the characters that are not allowed in a logfmt key with underscores.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// quoteLogfmtIfNeeded quotes a logfmt value if it is empty or contains spaces, equals signs, quotes or non-printable characters.
+// Quotes, backslashes and newlines are escaped.
+func quoteLogfmtIfNeeded(s string) string {
+	if s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r)
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// formatValue renders any log attribute value as text.
+// Strings that contain spaces, quotes or non-printable characters are quoted.
+//
+// formatValue never fails: values that cannot be rendered (e.g. a String method that panics) are replaced by a description of the failure,
+// cyclic values are rendered as <cycle> and the values nested deeper than maxValueDepth as <max depth>.
+func formatValue(v any) string {
+	return new(valueFormatter).format(v)
+}
+
+// formatRawValue renders any log attribute value as text, without quoting it.
+// It reports if the value is a text (e.g. string, error, fmt.Stringer), that might need quoting by the caller.
+func formatRawValue(v any) (formatted string, isText bool) {
+	return new(valueFormatter).formatRaw(v)
+}
+
+// valueFormatter renders a value and the values nested in it.
+// It keeps track of the pointers, maps and slices being rendered to detect cycles, and of the nesting depth.
+type valueFormatter struct {
+	visiting map[uintptr]bool
+	depth    int
+}
+
+// format renders a value as formatValue does.
+func (f *valueFormatter) format(v any) string {
+	formatted, isText := f.formatRaw(v)
+	if isText {
+		return quoteIfNeeded(formatted)
+	}
+	return formatted
+}
+
+// formatRaw renders a value as formatRawValue does, nested values (e.g. slice elements) are rendered using format.
+func (f *valueFormatter) formatRaw(v any) (formatted string, isText bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			formatted, isText = fmt.Sprintf("<panic: %v>", r), false
+		}
+	}()
+
+	if v == nil {
+		return nilValue, false
+	}
+
+	// typed nil pointers must not reach the error and fmt.Stringer methods
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		if rv.IsNil() {
+			return nilValue, false
+		}
+	}
+
+	switch value := v.(type) {
+	case string:
+		return value, true
+	case *ErrorValue:
+		if len(value.Chain) == 0 && len(value.Stack) == 0 {
+			return value.Message, true
+		}
+		return formatErrorValue(value), false
+	case error:
+		return value.Error(), true
+	case time.Time:
+		return value.Format(time.RFC3339Nano), false
+	case time.Duration:
+		return value.String(), false
+	case fmt.Stringer:
+		return value.String(), true
+	case []byte:
+		return string(value), true
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), false
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("%f", rv.Float()), false
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprintf("%v", rv.Complex()), false
+	case reflect.String:
+		return rv.String(), true
+	}
+
+	if f.depth >= maxValueDepth {
+		return maxDepthValue, false
+	}
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		pointer := rv.Pointer()
+		if f.visiting[pointer] {
+			return cycleValue, false
+		}
+		if f.visiting == nil {
+			f.visiting = make(map[uintptr]bool)
+		}
+		f.visiting[pointer] = true
+		defer delete(f.visiting, pointer)
+	}
+	f.depth++
+	defer func() { f.depth-- }()
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return f.formatRaw(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		elements := make([]string, rv.Len())
+		for i := range rv.Len() {
+			elements[i] = f.format(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(elements, " ") + "]", false
+	case reflect.Map:
+		entries := make([]string, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			entries = append(entries, f.format(iter.Key().Interface())+":"+f.format(iter.Value().Interface()))
+		}
+		sort.Strings(entries)
+		return "{" + strings.Join(entries, " ") + "}", false
+	case reflect.Struct:
+		fields := make([]string, 0, rv.NumField())
+		for i := range rv.NumField() {
+			if !rv.Type().Field(i).IsExported() {
+				continue
+			}
+			fields = append(fields, rv.Type().Field(i).Name+":"+f.format(rv.Field(i).Interface()))
+		}
+		return "{" + strings.Join(fields, " ") + "}", false
+	default:
+		return fmt.Sprintf("%v", v), true
+	}
+}
+
+// quoteIfNeeded quotes a string if it is empty or contains spaces, quotes or non-printable characters.
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || !unicode.IsPrint(r)
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,256 @@
+package logging
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testStringer struct{}
+
+func (testStringer) String() string { return "test stringer" }
+
+type testPanicStringer struct{}
+
+func (testPanicStringer) String() string { panic("test panic") }
+
+type testStruct struct {
+	Name    string
+	Count   int
+	private bool
+}
+
+type testNode struct {
+	Name string
+	Next *testNode
+}
+
+func TestFormatValueTableDriven(t *testing.T) {
+	type TestCase struct {
+		TestName string
+		Data     any
+		Expected string
+	}
+
+	var nilPointer *testStruct
+	var nilError error
+	count := 3
+	cyclicNode := &testNode{Name: "node"}
+	cyclicNode.Next = cyclicNode
+	cyclicSlice := []any{1, nil}
+	cyclicSlice[1] = cyclicSlice
+	cyclicMap := map[string]any{}
+	cyclicMap["self"] = cyclicMap
+	sharedNode := &testNode{Name: "shared"}
+	deepValue := any(1)
+	for range maxValueDepth + 1 {
+		deepValue = []any{deepValue}
+	}
+
+	testCases := []TestCase{
+		{TestName: "Nil", Data: nil, Expected: "<nil>"},
+		{TestName: "Nil pointer", Data: nilPointer, Expected: "<nil>"},
+		{TestName: "Nil error", Data: nilError, Expected: "<nil>"},
+		{TestName: "Int", Data: -1, Expected: "-1"},
+		{TestName: "Uint", Data: uint8(2), Expected: "2"},
+		{TestName: "Float", Data: 3.14, Expected: "3.140000"},
+		{TestName: "Bool", Data: true, Expected: "true"},
+		{TestName: "String", Data: "string", Expected: "string"},
+		{TestName: "String with spaces", Data: "two words", Expected: `"two words"`},
+		{TestName: "String with quotes", Data: `a"b`, Expected: `"a\"b"`},
+		{TestName: "Empty string", Data: "", Expected: `""`},
+		{TestName: "Bytes", Data: []byte("bytes"), Expected: "bytes"},
+		{TestName: "Error", Data: errors.New("test error"), Expected: `"test error"`},
+		{TestName: "Duration", Data: 1500 * time.Millisecond, Expected: "1.5s"},
+		{TestName: "Time", Data: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Expected: "2024-01-02T03:04:05Z"},
+		{TestName: "Stringer", Data: testStringer{}, Expected: `"test stringer"`},
+		{TestName: "Panicking stringer", Data: testPanicStringer{}, Expected: "<panic: test panic>"},
+		{TestName: "Pointer", Data: &count, Expected: "3"},
+		{TestName: "Slice", Data: []any{1, "a b", nil}, Expected: `[1 "a b" <nil>]`},
+		{TestName: "Map", Data: map[string]any{"b": 2, "a": []int{1}}, Expected: "{a:[1] b:2}"},
+		{TestName: "Struct", Data: testStruct{Name: "name", Count: 1}, Expected: "{Name:name Count:1}"},
+		{TestName: "Cyclic pointer", Data: cyclicNode, Expected: "{Name:node Next:<cycle>}"},
+		{TestName: "Cyclic slice", Data: cyclicSlice, Expected: "[1 <cycle>]"},
+		{TestName: "Cyclic map", Data: cyclicMap, Expected: "{self:<cycle>}"},
+		{TestName: "Shared pointer", Data: []any{sharedNode, sharedNode}, Expected: "[{Name:shared Next:<nil>} {Name:shared Next:<nil>}]"},
+		{TestName: "Too deep", Data: deepValue, Expected: strings.Repeat("[", maxValueDepth) + "<max depth>" + strings.Repeat("]", maxValueDepth)},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.TestName, func(t *testing.T) {
+			assert.Equal(t, test.Expected, formatValue(test.Data))
+		})
+	}
+}
+
+func TestFormatLoggerData(t *testing.T) {
+	loggerData := &LoggerData{
+		LoggerLevel: LevelInfo,
+		Timestamp:   now,
+		Message:     "test",
+		MetaData: map[string]any{
+			"varErr": errors.New("test error"),
+		},
+	}
+
+	assert.Equal(t, logFormat(*loggerData)+` [varErr="test error"]`, formatLoggerData(loggerData))
+}
+
+func TestFormatLoggerDataCyclicValue(t *testing.T) {
+	node := &testNode{Name: "node"}
+	node.Next = node
+	loggerData := &LoggerData{
+		LoggerLevel: LevelInfo,
+		Timestamp:   now,
+		Message:     "test",
+		MetaData:    map[string]any{"node": node},
+	}
+
+	assert.Equal(t, logFormat(*loggerData)+" [node={Name:node Next:<cycle>}]", formatLoggerData(loggerData))
+}
+
+func TestFormatLoggerDataSortedKeys(t *testing.T) {
+	loggerData := &LoggerData{
+		LoggerLevel: LevelInfo,
+		Timestamp:   now,
+		Message:     "test",
+		MetaData: map[string]any{
+			"c": 3,
+			"a": 1,
+			"b": 2,
+		},
+	}
+
+	for range 10 {
+		assert.Equal(t, logFormat(*loggerData)+" [a=1] [b=2] [c=3]", formatLoggerData(loggerData))
+	}
+}
+
+func TestMetaDataKeysWithInsertionOrder(t *testing.T) {
+	loggerData := &LoggerData{
+		MetaData: map[string]any{
+			"z":     1,
+			"a":     2,
+			"bound": 3,
+		},
+		MetaDataOrder: []string{"z", "a", "missing"},
+	}
+
+	assert.Equal(t, []string{"z", "a", "bound"}, loggerData.MetaDataKeys())
+}
+
+func TestFormatLogfmtLoggerDataEscaping(t *testing.T) {
+	loggerData := &LoggerData{
+		LoggerLevel: LevelWarning,
+		Timestamp:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message:     "multi\nline \"message\"",
+		MetaData: map[string]any{
+			"equation": "a=b",
+			"empty":    "",
+			"bad key":  []int{1, 2},
+			"path":     `C:\dir`,
+			"error":    &ErrorValue{Message: "not found"},
+		},
+	}
+
+	assert.Equal(t, `ts=2024-01-02T03:04:05Z level=warning msg="multi\nline \"message\"" bad_key="[1 2]" empty="" equation="a=b" error="not found" path="C:\\dir"`, formatLogfmtLoggerData(loggerData))
+}
+
+// testNestedTransactionLoggerData returns a transaction with a log before, inside and after a nested sub-transaction.
+func testNestedTransactionLoggerData() *TransactionLoggerData {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &TransactionLoggerData{
+		LoggerLevel:   LevelInfo,
+		Status:        TransactionStatusError,
+		StatusMessage: "card declined",
+		TransactionLogs: []*LoggerData{
+			{LoggerLevel: LevelInfo, Timestamp: ts, Message: "before"},
+			{LoggerLevel: LevelInfo, Timestamp: ts.Add(4 * time.Second), Message: "after"},
+		},
+		SubTransactions: []*SubTransactionLoggerData{
+			{
+				TransactionID:  "payment",
+				StartTimestamp: ts.Add(time.Second),
+				EndTimestamp:   ts.Add(3 * time.Second),
+				TransactionLoggerData: TransactionLoggerData{
+					LoggerLevel: LevelInfo,
+					SubTransactions: []*SubTransactionLoggerData{
+						{
+							TransactionID:  "charge card",
+							StartTimestamp: ts.Add(time.Second),
+							EndTimestamp:   ts.Add(2 * time.Second),
+							TransactionLoggerData: TransactionLoggerData{
+								LoggerLevel:     LevelInfo,
+								Status:          TransactionStatusError,
+								StatusMessage:   "card declined",
+								TransactionLogs: []*LoggerData{{LoggerLevel: LevelInfo, Timestamp: ts.Add(time.Second), Message: "charged"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestFormatTransactionLoggerDataWithSubTransactions(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	output := formatTransactionLoggerData(testTransactionId, ts, ts.Add(5*time.Second), testNestedTransactionLoggerData())
+
+	assert.Equal(t, ""+
+		"[2024-01-02 03:04:05.0000] Transaction {testTransaction} started!\n"+
+		"--> [2024-01-02 03:04:05.0000] [info] before\n"+
+		"--> [2024-01-02 03:04:06.0000] Sub-transaction {payment} started!\n"+
+		"----> [2024-01-02 03:04:06.0000] Sub-transaction {charge card} started!\n"+
+		"------> [2024-01-02 03:04:06.0000] [info] charged\n"+
+		"----> [2024-01-02 03:04:07.0000] Sub-transaction {charge card} ended! [status=error] [statusMessage=\"card declined\"] [duration=1s]\n"+
+		"--> [2024-01-02 03:04:08.0000] Sub-transaction {payment} ended! [duration=2s]\n"+
+		"--> [2024-01-02 03:04:09.0000] [info] after\n"+
+		"[2024-01-02 03:04:10.0000] Transaction {testTransaction} ended! [status=error] [statusMessage=\"card declined\"] [duration=5s]\n", output)
+}
+
+func TestFormatLogfmtTransactionLoggerDataWithSubTransactions(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	output := formatLogfmtTransactionLoggerData(testTransactionId, ts, ts.Add(5*time.Second), testNestedTransactionLoggerData())
+
+	assert.Equal(t, ""+
+		"ts=2024-01-02T03:04:05Z level=info transactionId=testTransaction msg=\"transaction started\"\n"+
+		"ts=2024-01-02T03:04:05Z level=info transactionId=testTransaction msg=before\n"+
+		"ts=2024-01-02T03:04:06Z level=info transactionId=testTransaction subTransactionId=payment msg=\"sub-transaction started\"\n"+
+		"ts=2024-01-02T03:04:06Z level=info transactionId=testTransaction subTransactionId=\"payment/charge card\" msg=\"sub-transaction started\"\n"+
+		"ts=2024-01-02T03:04:06Z level=info transactionId=testTransaction subTransactionId=\"payment/charge card\" msg=charged\n"+
+		"ts=2024-01-02T03:04:07Z level=info transactionId=testTransaction subTransactionId=\"payment/charge card\" msg=\"sub-transaction ended\" status=error statusMessage=\"card declined\" duration=1s\n"+
+		"ts=2024-01-02T03:04:08Z level=info transactionId=testTransaction subTransactionId=payment msg=\"sub-transaction ended\" duration=2s\n"+
+		"ts=2024-01-02T03:04:09Z level=info transactionId=testTransaction msg=after\n"+
+		"ts=2024-01-02T03:04:10Z level=info transactionId=testTransaction msg=\"transaction ended\" status=error statusMessage=\"card declined\" duration=5s\n", output)
+}
+
+func TestFormatTransactionOutcomeWithDroppedLogs(t *testing.T) {
+	tests := []struct {
+		name                  string
+		transactionLoggerData *TransactionLoggerData
+		expected              string
+	}{
+		{
+			name:                  "dropped logs",
+			transactionLoggerData: &TransactionLoggerData{Status: TransactionStatusOk, DroppedLogs: 3},
+			expected:              " [status=ok] [droppedLogs=3] [duration=1s]",
+		},
+		{
+			name:                  "partial chunk",
+			transactionLoggerData: &TransactionLoggerData{Partial: true},
+			expected:              " [partial=true] [duration=1s]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatTransactionOutcome(tt.transactionLoggerData, time.Second))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ConvertJSONFileToJSONLines converts a file written by the JSON array writers (JSONLogOutputFileWrite, JSONTransactionLogOutputFileWrite)
+// into a JSON Lines (NDJSON) file, one JSON object per line, as written by the JSON Lines writers.
+//
+// The destination file is created, or appended to if it already exists. The source file is not modified.
+func ConvertJSONFileToJSONLines(srcFileName string, dstFileName string) error {
+	src, err := os.Open(srcFileName)
+	if err != nil {
+		return fmt.Errorf("error: could not open json file %v", err)
+	}
+	defer src.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(src))
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("error: could not decode json file %v", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("error: the json file does not hold an array %s", srcFileName)
+	}
+
+	defer lockFile(dstFileName)()
+
+	dst, err := os.OpenFile(dstFileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error: could not open json lines file %v", err)
+	}
+	defer dst.Close()
+
+	writer := bufio.NewWriter(dst)
+	for decoder.More() {
+		var entry json.RawMessage
+		err = decoder.Decode(&entry)
+		if err != nil {
+			return fmt.Errorf("error: could not decode json file %v", err)
+		}
+
+		var line bytes.Buffer
+		err = json.Compact(&line, entry)
+		if err != nil {
+			return fmt.Errorf("error: could not compact json entry %v", err)
+		}
+		line.WriteByte('\n')
+
+		_, err = writer.Write(line.Bytes())
+		if err != nil {
+			return fmt.Errorf("error: could not write json lines file %v", err)
+		}
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return fmt.Errorf("error: could not write json lines file %v", err)
+	}
+	return nil
+}
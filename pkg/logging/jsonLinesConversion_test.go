@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"encoding/json"
+	"go-telemetry/pkg/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const jsonLinesConversionTestDirName = "testjsonlinesconversion"
+
+func TestConvertJSONFileToJSONLines(t *testing.T) {
+	setupTestEnvironment(t, jsonLinesConversionTestDirName)
+
+	srcFileName := filepath.Join(config.LoggerConfig.Logger.OutputDir, "source.json")
+	dstFileName := filepath.Join(config.LoggerConfig.Logger.OutputDir, "destination.jsonl")
+	err := os.WriteFile(srcFileName, []byte("[\n  {\n    \"message\": \"test1\"\n  },\n  {\n    \"message\": \"test2\",\n    \"metaData\": {\n      \"varInt\": 1\n    }\n  }\n]"), 0644)
+	if err != nil {
+		t.Fatalf("fatal: could not write json file %v", err)
+	}
+	t.Cleanup(func() {
+		cleanup(t, []string{"source.json", "destination.jsonl"})
+	})
+
+	err = ConvertJSONFileToJSONLines(srcFileName, dstFileName)
+	if err != nil {
+		t.Fatalf("fatal: could not convert json file %v", err)
+	}
+
+	b, err := os.ReadFile(dstFileName)
+	if err != nil {
+		t.Fatalf("error: could not read from json lines file %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	assert.Equal(t, []string{`{"message":"test1"}`, `{"message":"test2","metaData":{"varInt":1}}`}, lines)
+	for _, line := range lines {
+		assert.True(t, json.Valid([]byte(line)))
+	}
+}
+
+func TestConvertJSONFileToJSONLinesWithInvalidFile(t *testing.T) {
+	setupTestEnvironment(t, jsonLinesConversionTestDirName)
+
+	srcFileName := filepath.Join(config.LoggerConfig.Logger.OutputDir, "invalid.json")
+	err := os.WriteFile(srcFileName, []byte(`{"message": "test"}`), 0644)
+	if err != nil {
+		t.Fatalf("fatal: could not write json file %v", err)
+	}
+	t.Cleanup(func() {
+		cleanup(t, []string{"invalid.json"})
+	})
+
+	err = ConvertJSONFileToJSONLines(srcFileName, filepath.Join(config.LoggerConfig.Logger.OutputDir, "invalid.jsonl"))
+	assert.EqualError(t, err, "error: the json file does not hold an array "+srcFileName)
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Level is the severity of a log, and the minimum severity of the logs printed by a logger (see WithLoggerLevel).
+// It is written as its name by the text and JSON writers, and read from its name, see ParseLevel.
+type Level string
+
+// ParseLevel returns the level of the given name, case-insensitive (e.g. "info", "WARNING").
+// "warn" is accepted as the name of LevelWarning.
+func ParseLevel(name string) (Level, error) {
+	switch level := Level(strings.ToLower(strings.TrimSpace(name))); level {
+	case LevelTrace, LevelDebug, LevelInfo, LevelWarning, LevelError, LevelPanic, LevelFatal, LevelOff:
+		return level, nil
+	case "warn":
+		return LevelWarning, nil
+	default:
+		return "", fmt.Errorf("error: unknown logger level %q", name)
+	}
+}
+
+// String returns the name of the level.
+func (level Level) String() string {
+	return string(level)
+}
+
+// MarshalText encodes the level as its name, implementing encoding.TextMarshaler, e.g. for the JSON writers.
+// An unknown level is encoded as is, so that writing a log never fails because of its level.
+func (level Level) MarshalText() ([]byte, error) {
+	if parsed, err := ParseLevel(string(level)); err == nil {
+		level = parsed
+	}
+	return []byte(level), nil
+}
+
+// UnmarshalText decodes the level from its name, implementing encoding.TextUnmarshaler, e.g. for the JSON and YAML decoders.
+func (level *Level) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*level = parsed
+	return nil
+}
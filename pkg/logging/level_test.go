@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevelTableDriven(t *testing.T) {
+	tests := []struct {
+		name        string
+		expected    Level
+		expectedErr bool
+	}{
+		{name: "trace", expected: LevelTrace},
+		{name: "debug", expected: LevelDebug},
+		{name: "info", expected: LevelInfo},
+		{name: "warning", expected: LevelWarning},
+		{name: "warn", expected: LevelWarning},
+		{name: "error", expected: LevelError},
+		{name: "panic", expected: LevelPanic},
+		{name: "fatal", expected: LevelFatal},
+		{name: "off", expected: LevelOff},
+		{name: " ERROR ", expected: LevelError},
+		{name: "Info", expected: LevelInfo},
+		{name: "", expectedErr: true},
+		{name: "verbose", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, err := ParseLevel(tt.name)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, level)
+		})
+	}
+}
+
+func TestLevelJSON(t *testing.T) {
+	var decoded struct {
+		Level Level `json:"level"`
+	}
+	err := json.Unmarshal([]byte(`{"level":"WARN"}`), &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, LevelWarning, decoded.Level)
+
+	err = json.Unmarshal([]byte(`{"level":"verbose"}`), &decoded)
+	assert.Error(t, err)
+
+	encoded, err := json.Marshal(struct {
+		Levels []Level `json:"levels"`
+	}{Levels: []Level{LevelTrace, LevelFatal, Level("ERROR"), Level("unknown")}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"levels":["trace","fatal","error","unknown"]}`, string(encoded))
+}
+
+func TestLoggerLevelReachedTableDriven(t *testing.T) {
+	tests := []struct {
+		loggerLevel Level
+		threshold   Level
+		expected    bool
+	}{
+		{LevelTrace, LevelTrace, true},
+		{LevelTrace, LevelDebug, false},
+		{LevelDebug, LevelDebug, true},
+		{LevelInfo, LevelDebug, true},
+		{LevelDebug, LevelInfo, false},
+		{LevelWarning, LevelError, false},
+		{LevelError, LevelWarning, true},
+		{LevelError, LevelError, true},
+		{LevelPanic, LevelError, true},
+		{LevelError, LevelPanic, false},
+		{LevelFatal, LevelPanic, true},
+		{LevelPanic, LevelFatal, false},
+		{LevelError, LevelOff, false},
+		{LevelFatal, LevelOff, false},
+		{LevelOff, LevelOff, false},
+		{LevelOff, LevelTrace, false},
+		{Level("unknown"), LevelInfo, true},
+		{Level("unknown"), LevelWarning, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.loggerLevel)+"-"+string(tt.threshold), func(t *testing.T) {
+			assert.Equal(t, tt.expected, loggerLevelReached(tt.loggerLevel, tt.threshold))
+		})
+	}
+}
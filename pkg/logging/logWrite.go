@@ -3,13 +3,8 @@ package logging
 import (
 	"encoding/json"
 	"fmt"
-	"go-telemetry/pkg/internal/config"
 	"io"
 	"os"
-	"path/filepath"
-	"reflect"
-	"strings"
-	"time"
 )
 
 // A LogOutputWriter is a output writer function for standard logging.
@@ -18,94 +13,84 @@ type LogOutputWriter func(*LoggerData) error
 // CLILogOutputWrite returns an output writer that prints the logs to the CLI.
 func CLILogOutputWrite() LogOutputWriter {
 	return func(loggerData *LoggerData) error {
-		fmt.Printf("[%s] [%s] %s", loggerData.Timestamp.Format(timestampFormat), loggerData.LoggerLevel, loggerData.Message)
-		for k, v := range loggerData.MetaData {
-			typeName := reflect.TypeOf(v).Name()
-			if strings.Contains(typeName, "int") {
-				fmt.Printf(" [%s=%d]", k, v)
-			} else if strings.Contains(typeName, "float") {
-				fmt.Printf(" [%s=%f]", k, v)
-			} else if typeName == "string" {
-				fmt.Printf(" [%s=%s]", k, v)
-			} else {
-				fmt.Printf("\n")
-				return fmt.Errorf("error: formatting for this variable type is not implement %s", typeName)
-			}
+		_, err := fmt.Println(formatLoggerData(loggerData))
+		if err != nil {
+			return fmt.Errorf("error: could not write to the CLI %v", err)
 		}
-		fmt.Printf("\n")
 		return nil
 	}
 }
 
-// JSONLogOutputFileWrite returns an output writer that prints the logs to a JSON file.
-func JSONLogOutputFileWrite() LogOutputWriter {
+// LogfmtLogOutputWrite returns an output writer that prints the logs to the CLI, in logfmt format (ts=... level=info msg="..." key=value).
+func LogfmtLogOutputWrite() LogOutputWriter {
 	return func(loggerData *LoggerData) error {
-		fileName := fmt.Sprintf(filepath.Join(config.LoggerConfig.Logger.OutputDir, "%s.json"), time.Now().Format(fileTimestampFormat))
-		f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE, 0644)
+		_, err := fmt.Println(formatLogfmtLoggerData(loggerData))
 		if err != nil {
-			return fmt.Errorf("error: could not open json file %v", err)
+			return fmt.Errorf("error: could not write to the CLI %v", err)
 		}
-		defer f.Close()
+		return nil
+	}
+}
 
-		ret, err := f.Seek(-int64(len([]byte(endArray))), io.SeekEnd)
-		if err != nil {
-			_, err := f.Write([]byte(startArray))
+// JSONLogOutputFileWrite returns an output writer that prints the logs to a JSON file.
+func JSONLogOutputFileWrite(options ...func(*fileSink)) LogOutputWriter {
+	sink := newFileSink(".json", os.O_WRONLY|os.O_CREATE, options...)
+	return func(loggerData *LoggerData) error {
+		return sink.write(func(f *os.File) error {
+			ret, err := f.Seek(-int64(len([]byte(endArray))), io.SeekEnd)
 			if err != nil {
-				return fmt.Errorf("error: could not write json file %v", err)
+				_, err := f.Write([]byte(startArray))
+				if err != nil {
+					return fmt.Errorf("error: could not write json file %v", err)
+				}
+				ret = int64(len([]byte(startArray)))
 			}
-			ret = int64(len([]byte(startArray)))
-		}
 
-		loggerDataBytes, err := json.MarshalIndent(loggerData, indent, indent)
-		if err != nil {
-			return fmt.Errorf("error: could not marshal logger data %v", err)
-		}
+			loggerDataBytes, err := json.MarshalIndent(loggerData, indent, indent)
+			if err != nil {
+				return fmt.Errorf("error: could not marshal logger data %v", err)
+			}
 
-		writtenDataBytes := []byte(objectDelimiter)
-		if ret == int64(len([]byte(startArray))) {
-			writtenDataBytes = []byte(indent)
-		}
-		writtenDataBytes = append(writtenDataBytes, loggerDataBytes...)
+			writtenDataBytes := []byte(objectDelimiter)
+			if ret == int64(len([]byte(startArray))) {
+				writtenDataBytes = []byte(indent)
+			}
+			writtenDataBytes = append(writtenDataBytes, loggerDataBytes...)
 
-		_, err = f.WriteAt(writtenDataBytes, ret)
-		if err != nil {
-			return fmt.Errorf("error: could not write json file %v", err)
-		}
+			_, err = f.WriteAt(writtenDataBytes, ret)
+			if err != nil {
+				return fmt.Errorf("error: could not write json file %v", err)
+			}
 
-		_, err = f.WriteAt([]byte(endArray), ret+int64(len(writtenDataBytes)))
-		if err != nil {
-			return fmt.Errorf("error: could not write json file %v", err)
-		}
+			_, err = f.WriteAt([]byte(endArray), ret+int64(len(writtenDataBytes)))
+			if err != nil {
+				return fmt.Errorf("error: could not write json file %v", err)
+			}
 
-		return nil
+			return nil
+		})
 	}
 }
 
-// TextLogOutputFileWrite returns an output writer that prints the logs to a text file.
-func TextLogOutputFileWrite() LogOutputWriter {
+// JSONLinesLogOutputFileWrite returns an output writer that appends the logs to a JSON Lines (NDJSON) file, one JSON object per line.
+//
+// Unlike JSONLogOutputFileWrite, the file is never rewritten, so it stays valid if the process crashes and can be followed by standard tools.
+func JSONLinesLogOutputFileWrite(options ...func(*fileSink)) LogOutputWriter {
+	sink := newFileSink(".jsonl", os.O_WRONLY|os.O_CREATE|os.O_APPEND, options...)
 	return func(loggerData *LoggerData) error {
-		fileName := fmt.Sprintf(filepath.Join(config.LoggerConfig.Logger.OutputDir, "%s.log"), time.Now().Format(fileTimestampFormat))
-		f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		loggerDataBytes, err := json.Marshal(loggerData)
 		if err != nil {
-			return fmt.Errorf("error: could not open text file %v", err)
+			return fmt.Errorf("error: could not marshal logger data %v", err)
 		}
-		defer f.Close()
 
-		f.WriteString(fmt.Sprintf("[%s] [%s] %s", loggerData.Timestamp.Format(timestampFormat), loggerData.LoggerLevel, loggerData.Message))
-		for k, v := range loggerData.MetaData {
-			typeName := reflect.TypeOf(v).Name()
-			if strings.Contains(typeName, "int") {
-				f.WriteString(fmt.Sprintf(" [%s=%d]", k, v))
-			} else if strings.Contains(typeName, "float") {
-				f.WriteString(fmt.Sprintf(" [%s=%f]", k, v))
-			} else if typeName == "string" {
-				f.WriteString(fmt.Sprintf(" [%s=%s]", k, v))
-			} else {
-				f.WriteString("\n")
-				return fmt.Errorf("error: formatting for this variable type is not implement %s", typeName)
-			}
-		}
-		f.WriteString("\n")
-		return nil
+		return sink.append(append(loggerDataBytes, '\n'))
+	}
+}
+
+// TextLogOutputFileWrite returns an output writer that prints the logs to a text file.
+func TextLogOutputFileWrite(options ...func(*fileSink)) LogOutputWriter {
+	sink := newFileSink(".log", os.O_WRONLY|os.O_CREATE|os.O_APPEND, options...)
+	return func(loggerData *LoggerData) error {
+		return sink.append([]byte(formatLoggerData(loggerData) + "\n"))
 	}
 }
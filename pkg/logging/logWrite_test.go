@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -96,6 +97,10 @@ func TestTextLogOutputFileWrite(t *testing.T) {
 	if err != nil {
 		t.Fatalf("fatal: could not write logs to text file %v", err)
 	}
+	err = FlushFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not flush the file sinks %v", err)
+	}
 
 	f, err := os.OpenFile(filepath.Join(config.LoggerConfig.Logger.OutputDir, generatedFileName), os.O_RDONLY, 0644)
 	if err != nil {
@@ -116,3 +121,54 @@ func TestTextLogOutputFileWrite(t *testing.T) {
 	assert.Contains(t, output, fmt.Sprintf("varFloat=%f", testLogging.MetaData["varFloat"]))
 	assert.Contains(t, output, "\n")
 }
+
+func TestLogfmtLogOutputWrite(t *testing.T) {
+	output, err := itesting.CaptureOutput(func() error {
+		err := LogfmtLogOutputWrite()(&testLogging)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("fatal: could not capture stdout output %v", err)
+	}
+
+	assert.Equal(t, fmt.Sprintf("ts=%s level=info msg=test varFloat=3.140000 varInt=0 varStr=string\n", now.Format(time.RFC3339Nano)), output)
+}
+
+func TestJSONLinesLogOutputFileWrite(t *testing.T) {
+	setupTestEnvironment(t, logTestDirName)
+
+	generatedFileName := fmt.Sprintf("%s.jsonl", time.Now().Format(fileTimestampFormat))
+	t.Cleanup(func() {
+		cleanup(t, []string{generatedFileName})
+	})
+
+	writer := JSONLinesLogOutputFileWrite()
+	for range 2 {
+		err := writer(&testLogging)
+		if err != nil {
+			t.Fatalf("fatal: could not write logs to json lines file %v", err)
+		}
+	}
+	err := FlushFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not flush the file sinks %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(config.LoggerConfig.Logger.OutputDir, generatedFileName))
+	if err != nil {
+		t.Fatalf("error: could not read from json lines file %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	for _, line := range lines {
+		var log LoggerData
+		if err = json.Unmarshal([]byte(line), &log); err != nil {
+			t.Fatalf("error: could not decode json line %v", err)
+		}
+		assert.True(t, testLogging.Timestamp.Equal(log.Timestamp))
+		assert.Equal(t, testLogging.LoggerLevel, log.LoggerLevel)
+		assert.Equal(t, testLogging.Message, log.Message)
+		assert.Equal(t, testLogging.MetaData["varStr"], log.MetaData["varStr"])
+	}
+}
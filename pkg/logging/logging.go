@@ -3,6 +3,7 @@ package logging
 import (
 	"fmt"
 	"go-telemetry/pkg/internal/config"
+	"sort"
 	"sync"
 	"time"
 )
@@ -10,66 +11,141 @@ import (
 // A MetaData holds the log variables
 type MetaData = map[string]any
 
+// A Field is a log attribute, used by the ordered field API (InfoFields, WarningFields...) to keep the attributes in the given order
+type Field struct {
+	Key   string
+	Value any
+}
+
 // A LoggerData is a user defined log, that takes the timestamp of when the log was initialized
 type LoggerData struct {
-	LoggerLevel loggerLevel `json:"loggerLevel"`
-	Timestamp   time.Time   `json:"timestamp"`
-	Message     string      `json:"message"`
-	MetaData    MetaData    `json:"metaData"`
+	LoggerLevel   Level     `json:"loggerLevel"`
+	Timestamp     time.Time `json:"timestamp"`
+	Message       string    `json:"message"`
+	MetaData      MetaData  `json:"metaData"`
+	MetaDataOrder []string  `json:"-"`                // the insertion order of the MetaData keys, set by the ordered field API
+	Caller        *Caller   `json:"caller,omitempty"` // the code that registered the log, set if the logger captures its callers
 }
 
 // A logging holds the top-level configuration of the logger.
 // They can be configured via the YAML configuration file or by pre-defined "drivers" or self-created ones.
 type logging struct {
-	loggerLevel loggerLevel
+	loggerLevel Level
 	outputWrite LogOutputWriter
+	outputMutex *sync.Mutex
+	asyncWriter *asyncLogWriter
+	fields      MetaData
+	stdLogLevel Level // the level of the standard log package output redirected to the logger, not redirected if unset
+	caller      bool  // record the code registering every log, see WithCaller
+	callerSkip  int
+	errorStack  bool // record the stack in the Err attributes of the Error logs, see WithErrorStack
+}
+
+// MetaDataKeys returns the MetaData keys in a stable order: the keys of the ordered field API in insertion order,
+// followed by the remaining keys (e.g. bound using With) sorted alphabetically.
+func (loggerData *LoggerData) MetaDataKeys() []string {
+	keys := make([]string, 0, len(loggerData.MetaData))
+	ordered := make(map[string]bool, len(loggerData.MetaDataOrder))
+	for _, k := range loggerData.MetaDataOrder {
+		if _, ok := loggerData.MetaData[k]; ok && !ordered[k] {
+			ordered[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sortedFrom := len(keys)
+	for k := range loggerData.MetaData {
+		if !ordered[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys[sortedFrom:])
+	return keys
 }
 
 var loggerOnce sync.Once
 var loggerInstance *logging
-var writeLogOutputMutex sync.Mutex
 
-// NewLog creates a logging instance, respective to the defined YAML configuration or by given "drivers" in form of options argument.
+// NewLog returns the default logging instance, respective to the defined YAML configuration or by given "drivers" in form of options argument.
 // The "drivers" have a higher priority than YAML configuration.
 //
-// The log configuration cannot be change once set.
+// The log configuration cannot be change once set, the options of later calls are ignored.
+// Use NewLogInstance for independently configured loggers.
 //
 // Default:
 // If no configuration nor drivers are specified, the log level is Info with output to CLI.
 func NewLog(options ...func(*logging)) *logging {
 	loggerOnce.Do(func() {
-		config.Init()
+		loggerInstance = NewLogInstance(options...)
+	})
+	return loggerInstance
+}
+
+// NewLogInstance creates a new logging instance on every call, respective to the defined YAML configuration or by given "drivers" in form of options argument.
+// The "drivers" have a higher priority than YAML configuration.
+//
+// Every instance holds its own configuration and output synchronization, so multiple loggers can be used in the same program
+// (e.g. an audit logger to a JSON file and an application logger to the CLI).
+//
+// Default:
+// If no configuration nor drivers are specified, the log level is Info with output to CLI.
+func NewLogInstance(options ...func(*logging)) *logging {
+	config.Init()
 
-		loggerInstance = &logging{}
+	l := &logging{
+		outputMutex: &sync.Mutex{},
+	}
 
-		switch config.LoggerConfig.Logger.Level {
-		case string(LevelOff), string(LevelInfo), string(LevelWarning), string(LevelError), string(LevelDebug):
-			loggerInstance.loggerLevel = loggerLevel(config.LoggerConfig.Logger.Level)
-		default:
-			loggerInstance.loggerLevel = LevelInfo
-		}
+	l.loggerLevel = loggerLevelFromConfig(config.LoggerConfig.Logger.Level, LevelInfo)
+	if len(config.LoggerConfig.Logger.Outputs) > 0 {
+		l.outputWrite, l.loggerLevel = multiLogOutputWriteFromConfig(config.LoggerConfig.Logger)
+	} else {
+		l.outputWrite = logOutputWriterFromConfig(config.LoggerConfig.Logger.OutputWriter)
+	}
 
-		switch config.LoggerConfig.Logger.OutputWriter {
-		case string(cli):
-			loggerInstance.outputWrite = CLILogOutputWrite()
-		case string(jsonFile):
-			loggerInstance.outputWrite = JSONLogOutputFileWrite()
-		case string(textFile):
-			loggerInstance.outputWrite = TextLogOutputFileWrite()
-		default:
-			loggerInstance.outputWrite = CLILogOutputWrite()
-		}
+	// Log options override the YAML file configuration
+	for _, o := range options {
+		o(l)
+	}
 
-		// Log options override the YAML file configuration
-		for _, o := range options {
-			o(loggerInstance)
-		}
-	})
-	return loggerInstance
+	if l.asyncWriter != nil {
+		l.asyncWriter.start(l.writeLoggerData)
+	}
+	if l.stdLogLevel != "" {
+		l.redirectStdLog()
+	}
+	config.PrintWarnings()
+	return l
+}
+
+// loggerLevelFromConfig converts the level of the YAML configuration, falling back to the default level if it is unset or invalid.
+func loggerLevelFromConfig(level string, defaultLevel Level) Level {
+	loggerLevel, err := ParseLevel(level)
+	if err != nil {
+		return defaultLevel
+	}
+	return loggerLevel
+}
+
+// logOutputWriterFromConfig creates the output writer of the YAML configuration, falling back to the CLI if it is unset or invalid.
+func logOutputWriterFromConfig(outputWriter string) LogOutputWriter {
+	switch outputWriter {
+	case string(cli):
+		return CLILogOutputWrite()
+	case string(jsonFile):
+		return JSONLogOutputFileWrite()
+	case string(textFile):
+		return TextLogOutputFileWrite()
+	case string(logfmt):
+		return LogfmtLogOutputWrite()
+	case string(jsonLines):
+		return JSONLinesLogOutputFileWrite()
+	default:
+		return CLILogOutputWrite()
+	}
 }
 
 // WithLoggerLevel is a pre-defined "driver" that specifies the log level used
-func WithLoggerLevel(loggerLevel loggerLevel) func(*logging) {
+func WithLoggerLevel(loggerLevel Level) func(*logging) {
 	return func(l *logging) {
 		l.loggerLevel = loggerLevel
 	}
@@ -82,54 +158,134 @@ func WithLogOutputWriter(outputWriter LogOutputWriter) func(*logging) {
 	}
 }
 
+// With returns a derived logger that adds the given attributes to every log it registers.
+// The attributes given to a log method override the bound attributes with the same key.
+//
+// The derived logger shares the configuration and the output of its parent and can be derived further.
+func (l *logging) With(v MetaData) *logging {
+	derived := *l
+	derived.fields = mergeMetaData(l.fields, withErrorValues(v))
+	return &derived
+}
+
 // Info registers a log of level Info, with a message and additional attributes.
 //
 // If no attributes, use nil as MetaData
 func (l *logging) Info(msg string, v MetaData) {
-	l.processLoggerData(LevelInfo, msg, v)
+	l.processLoggerData(LevelInfo, msg, v, nil)
 }
 
 // Warning registers a log of level Warning, with a message and additional attributes.
 //
 // If no attributes, use nil as MetaData
 func (l *logging) Warning(msg string, v MetaData) {
-	l.processLoggerData(LevelWarning, msg, v)
+	l.processLoggerData(LevelWarning, msg, v, nil)
 }
 
 // Error registers a log of level Error, with a message and additional attributes.
 //
 // If no attributes, use nil as MetaData
 func (l *logging) Error(msg string, v MetaData) {
-	l.processLoggerData(LevelError, msg, v)
+	l.processLoggerData(LevelError, msg, v, nil)
 }
 
 // Debug registers a log of level Debug, with a message and additional attributes.
 //
 // If no attributes, use nil as MetaData
 func (l *logging) Debug(msg string, v MetaData) {
-	l.processLoggerData(LevelDebug, msg, v)
+	l.processLoggerData(LevelDebug, msg, v, nil)
+}
+
+// Trace registers a log of level Trace, with a message and additional attributes.
+//
+// If no attributes, use nil as MetaData
+func (l *logging) Trace(msg string, v MetaData) {
+	l.processLoggerData(LevelTrace, msg, v, nil)
+}
+
+// InfoFields registers a log of level Info, with a message and additional attributes kept in the given order.
+func (l *logging) InfoFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelInfo, msg, metaData, metaDataOrder)
+}
+
+// WarningFields registers a log of level Warning, with a message and additional attributes kept in the given order.
+func (l *logging) WarningFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelWarning, msg, metaData, metaDataOrder)
+}
+
+// ErrorFields registers a log of level Error, with a message and additional attributes kept in the given order.
+func (l *logging) ErrorFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelError, msg, metaData, metaDataOrder)
+}
+
+// DebugFields registers a log of level Debug, with a message and additional attributes kept in the given order.
+func (l *logging) DebugFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelDebug, msg, metaData, metaDataOrder)
+}
+
+// TraceFields registers a log of level Trace, with a message and additional attributes kept in the given order.
+func (l *logging) TraceFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelTrace, msg, metaData, metaDataOrder)
 }
 
 // processLoggerData initiates the writing of log data to the output by using the specified OutputWriter.
-// Logs are printed if the set level is higher or equal than the log method used (Info, Warning, Error, Debug).
-// Will block until the writing is finished.
+// Logs are printed if the log method used (Info, Warning, Error, Debug...) is at least as severe as the set level.
+// Will block until the writing is finished, unless the logger is asynchronous, in which case the log is queued.
 //
 // processLoggerData is safe to call concurrently with other operations and will
 // block until all other operations finish.
 //
 // If LogLevel is Off, no logs are printed.
-func (l *logging) processLoggerData(loggerLevel loggerLevel, msg string, metaData MetaData) {
-	if convertLoggerLevelToInt(loggerLevel) <= convertLoggerLevelToInt(l.loggerLevel) {
-		writeLogOutputMutex.Lock()
-		err := l.outputWrite(&LoggerData{
-			Timestamp:   time.Now(),
-			LoggerLevel: loggerLevel,
-			Message:     msg,
-			MetaData:    metaData,
-		})
-		if err != nil {
-			fmt.Println(err)
+func (l *logging) processLoggerData(loggerLevel Level, msg string, metaData MetaData, metaDataOrder []string) {
+	if l.enabled(loggerLevel) {
+		metaData = withErrorValues(metaData)
+		if l.errorStack && loggerLevelReached(loggerLevel, LevelError) && hasErrorValue(metaData) {
+			metaData = withErrorStack(metaData, captureStack(l.callerSkip+1)) // the log method (Info, InfoFields...) calling processLoggerData
+		}
+		loggerData := &LoggerData{
+			Timestamp:     time.Now(),
+			LoggerLevel:   loggerLevel,
+			Message:       msg,
+			MetaData:      metaData,
+			MetaDataOrder: metaDataOrder,
+		}
+		if l.caller {
+			loggerData.Caller = captureCaller(l.callerSkip + 1) // the log method (Info, InfoFields...) calling processLoggerData
 		}
-		writeLogOutputMutex.Unlock()
+		l.registerLoggerData(loggerData)
+	}
+}
+
+// enabled reports whether the logs of the given level are printed by the logger.
+func (l *logging) enabled(loggerLevel Level) bool {
+	return loggerLevelReached(loggerLevel, l.loggerLevel)
+}
+
+// registerLoggerData adds the bound attributes to the log data, then writes it or queues it if the logger is asynchronous.
+func (l *logging) registerLoggerData(loggerData *LoggerData) {
+	if len(l.fields) > 0 {
+		loggerData.MetaData = mergeMetaData(l.fields, loggerData.MetaData)
+	}
+	if l.asyncWriter != nil && l.asyncWriter.enqueue(loggerData) {
+		return
+	}
+	l.writeLoggerData(loggerData)
+}
+
+// writeLoggerData writes the log data to the output by using the specified OutputWriter.
+//
+// writeLoggerData is safe to call concurrently with other operations of the same instance and will
+// block until all other operations finish.
+func (l *logging) writeLoggerData(loggerData *LoggerData) {
+	l.outputMutex.Lock()
+	err := l.outputWrite(loggerData)
+	if err != nil {
+		fmt.Println(err)
 	}
+	l.outputMutex.Unlock()
 }
@@ -11,14 +11,16 @@ import (
 
 const (
 	// output writers fn names
-	CLILogOutputWriteName      = "CLILogOutputWrite"
-	JSONLogOutputFileWriteName = "JSONLogOutputFileWrite"
-	TextLogOutputFileWriteName = "TextLogOutputFileWrite"
+	CLILogOutputWriteName           = "CLILogOutputWrite"
+	JSONLogOutputFileWriteName      = "JSONLogOutputFileWrite"
+	TextLogOutputFileWriteName      = "TextLogOutputFileWrite"
+	LogfmtLogOutputWriteName        = "LogfmtLogOutputWrite"
+	JSONLinesLogOutputFileWriteName = "JSONLinesLogOutputFileWrite"
 )
 
 func TestNewLogWithYAMLConfigTableDriven(t *testing.T) {
 	type Expected struct {
-		Level            loggerLevel
+		Level            Level
 		OutputWriterName string
 	}
 	type TestCase struct {
@@ -83,6 +85,28 @@ func TestNewLogWithYAMLConfigTableDriven(t *testing.T) {
 				OutputWriterName: TextLogOutputFileWriteName,
 			},
 		},
+		{
+			TestName: "Log level warning, output writer logfmt",
+			Data: config.Logger{
+				Level:        string(LevelWarning),
+				OutputWriter: string(logfmt),
+			},
+			Expected: Expected{
+				Level:            LevelWarning,
+				OutputWriterName: LogfmtLogOutputWriteName,
+			},
+		},
+		{
+			TestName: "Log level debug, output writer json lines file",
+			Data: config.Logger{
+				Level:        string(LevelDebug),
+				OutputWriter: string(jsonLines),
+			},
+			Expected: Expected{
+				Level:            LevelDebug,
+				OutputWriterName: JSONLinesLogOutputFileWriteName,
+			},
+		},
 		{
 			TestName: "Log level error, output writer invalid value",
 			Data: config.Logger{
@@ -123,6 +147,31 @@ func TestNewLogWithYAMLConfigTableDriven(t *testing.T) {
 	}
 }
 
+func TestNewLogIsSingleton(t *testing.T) {
+	loggerOnce = sync.Once{}
+	log1 := NewLog(WithLoggerLevel(LevelDebug))
+	log2 := NewLog(WithLoggerLevel(LevelError))
+
+	assert.Same(t, log1, log2)
+	assert.Equal(t, LevelDebug, log2.loggerLevel)
+}
+
+func TestNewLogInstance(t *testing.T) {
+	var loggerData []*LoggerData
+	auditLog := NewLogInstance(WithLoggerLevel(LevelDebug), WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)))
+	appLog := NewLogInstance(WithLoggerLevel(LevelOff))
+
+	assert.NotSame(t, auditLog, appLog)
+	assert.NotSame(t, auditLog.outputMutex, appLog.outputMutex)
+	assert.Equal(t, LevelDebug, auditLog.loggerLevel)
+	assert.Equal(t, LevelOff, appLog.loggerLevel)
+
+	auditLog.Debug("test audit", nil)
+	appLog.Debug("test app", nil)
+
+	assert.Equal(t, []string{"test audit"}, loggerDataMessages(loggerData))
+}
+
 func TestWithLoggerLevel(t *testing.T) {
 	l := logging{}
 	WithLoggerLevel(LevelDebug)(&l)
@@ -182,8 +231,8 @@ func TestInfo(t *testing.T) {
 	assert.Contains(t, bytes, "varStr=string")
 	assert.Contains(t, bytes, "varFloat=3.14")
 	assert.Contains(t, bytes, "test info")
-	assert.NotContains(t, bytes, "test warning")
-	assert.NotContains(t, bytes, "test error")
+	assert.Contains(t, bytes, "test warning")
+	assert.Contains(t, bytes, "test error")
 	assert.NotContains(t, bytes, "test debug")
 }
 
@@ -209,9 +258,9 @@ func TestWarning(t *testing.T) {
 	assert.Contains(t, bytes, "varInt=0")
 	assert.Contains(t, bytes, "varStr=string")
 	assert.Contains(t, bytes, "varFloat=3.14")
-	assert.Contains(t, bytes, "test info")
+	assert.NotContains(t, bytes, "test info")
 	assert.Contains(t, bytes, "test warning")
-	assert.NotContains(t, bytes, "test error")
+	assert.Contains(t, bytes, "test error")
 	assert.NotContains(t, bytes, "test debug")
 }
 
@@ -237,8 +286,8 @@ func TestError(t *testing.T) {
 	assert.Contains(t, bytes, "varInt=0")
 	assert.Contains(t, bytes, "varStr=string")
 	assert.Contains(t, bytes, "varFloat=3.14")
-	assert.Contains(t, bytes, "test info")
-	assert.Contains(t, bytes, "test warning")
+	assert.NotContains(t, bytes, "test info")
+	assert.NotContains(t, bytes, "test warning")
 	assert.Contains(t, bytes, "test error")
 	assert.NotContains(t, bytes, "test debug")
 }
@@ -256,6 +305,7 @@ func TestDebug(t *testing.T) {
 		log.Info("test info", nil)
 		log.Warning("test warning", nil)
 		log.Error("test error", nil)
+		log.Trace("test trace", nil)
 		return nil
 	})
 	if err != nil {
@@ -269,4 +319,71 @@ func TestDebug(t *testing.T) {
 	assert.Contains(t, bytes, "test warning")
 	assert.Contains(t, bytes, "test error")
 	assert.Contains(t, bytes, "test debug")
+	assert.NotContains(t, bytes, "test trace")
+}
+
+func TestTrace(t *testing.T) {
+	loggerOnce = sync.Once{}
+	log := NewLog(WithLoggerLevel(LevelTrace))
+
+	bytes, err := itesting.CaptureOutput(func() error {
+		log.Trace("test trace", map[string]any{
+			"varInt":   0,
+			"varStr":   "string",
+			"varFloat": 3.14,
+		})
+		log.TraceFields("test trace fields", Field{"varInt", 1})
+		log.Debug("test debug", nil)
+		log.Error("test error", nil)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error: could not capture stdout output %v", err)
+	}
+
+	assert.Contains(t, bytes, "[trace] test trace")
+	assert.Contains(t, bytes, "varInt=0")
+	assert.Contains(t, bytes, "test trace fields [varInt=1]")
+	assert.Contains(t, bytes, "test debug")
+	assert.Contains(t, bytes, "test error")
+}
+
+func TestWith(t *testing.T) {
+	var loggerData []*LoggerData
+	log := NewLogInstance(WithLoggerLevel(LevelInfo), WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)))
+
+	requestLog := log.With(map[string]any{
+		"requestId": "1",
+		"service":   "test",
+	})
+	userLog := requestLog.With(map[string]any{
+		"userId": 2,
+	})
+
+	log.Info("test parent", nil)
+	requestLog.Info("test request", map[string]any{
+		"service": "override",
+	})
+	userLog.Info("test user", nil)
+
+	assert.Len(t, loggerData, 3)
+	assert.Nil(t, loggerData[0].MetaData)
+	assert.Equal(t, MetaData{"requestId": "1", "service": "override"}, loggerData[1].MetaData)
+	assert.Equal(t, MetaData{"requestId": "1", "service": "test", "userId": 2}, loggerData[2].MetaData)
+}
+
+func TestInfoFields(t *testing.T) {
+	log := NewLogInstance(WithLoggerLevel(LevelInfo))
+
+	bytes, err := itesting.CaptureOutput(func() error {
+		log.With(map[string]any{"service": "test"}).InfoFields("test info", Field{"varStr", "string"}, Field{"varInt", 0}, Field{"varStr", "override"})
+		log.DebugFields("test debug", Field{"varInt", 0})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error: could not capture stdout output %v", err)
+	}
+
+	assert.Contains(t, bytes, "test info [varStr=override] [varInt=0] [service=test]\n")
+	assert.NotContains(t, bytes, "test debug")
 }
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"errors"
+	"go-telemetry/pkg/internal/config"
+)
+
+// A LogOutput is one of the outputs of a multi output writer, that receives the logs enabled by its own level.
+type LogOutput struct {
+	Level        Level
+	OutputWriter LogOutputWriter
+}
+
+// MultiLogOutputWrite returns an output writer that dispatches every log to the given outputs,
+// each output receiving only the logs enabled by its level (e.g. Warning to the CLI and Debug to a JSON Lines file).
+//
+// An output that fails does not prevent the delivery to the other outputs, the errors of all outputs being returned together.
+//
+// The logger level is applied before the output levels, so it must enable every log wanted by at least one of the outputs.
+func MultiLogOutputWrite(outputs ...LogOutput) LogOutputWriter {
+	outputs = append([]LogOutput(nil), outputs...)
+	return func(loggerData *LoggerData) error {
+		var errs []error
+		for _, output := range outputs {
+			if output.OutputWriter == nil || !loggerLevelReached(loggerData.LoggerLevel, output.Level) {
+				continue
+			}
+			errs = append(errs, output.OutputWriter(loggerData))
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// multiLogOutputWriteFromConfig creates the multi output writer of the outputs list of the YAML configuration.
+// An output without a level uses the logger level.
+//
+// The returned logger level is the logger level of the configuration or, if unset, the most verbose level of the outputs,
+// so that every output receives the logs enabled by its level.
+func multiLogOutputWriteFromConfig(loggerConfig config.Logger) (LogOutputWriter, Level) {
+	level := loggerLevelFromConfig(loggerConfig.Level, LevelInfo)
+	mostVerboseLevel := LevelOff
+
+	outputs := make([]LogOutput, 0, len(loggerConfig.Outputs))
+	for _, output := range loggerConfig.Outputs {
+		outputLevel := loggerLevelFromConfig(output.Level, level)
+		if convertLoggerLevelToInt(outputLevel) < convertLoggerLevelToInt(mostVerboseLevel) {
+			mostVerboseLevel = outputLevel
+		}
+		outputs = append(outputs, LogOutput{
+			Level:        outputLevel,
+			OutputWriter: logOutputWriterFromConfig(output.OutputWriter),
+		})
+	}
+
+	if loggerConfig.Level == "" {
+		level = mostVerboseLevel
+	}
+	return MultiLogOutputWrite(outputs...), level
+}
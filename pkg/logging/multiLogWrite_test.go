@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"errors"
+	"go-telemetry/pkg/internal/config"
+	itesting "go-telemetry/pkg/internal/telemetrytesting"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	MultiLogOutputWriteName = "MultiLogOutputWrite"
+)
+
+func TestMultiLogOutputWrite(t *testing.T) {
+	var warningLoggerData, debugLoggerData []*LoggerData
+	log := NewLogInstance(WithLoggerLevel(LevelDebug), WithLogOutputWriter(MultiLogOutputWrite(
+		LogOutput{Level: LevelWarning, OutputWriter: recordingLoggerDataOutputWrite(&warningLoggerData)},
+		LogOutput{Level: LevelDebug, OutputWriter: recordingLoggerDataOutputWrite(&debugLoggerData)},
+		LogOutput{Level: LevelOff, OutputWriter: func(*LoggerData) error {
+			t.Errorf("error: the output with level off received a log")
+			return nil
+		}},
+	)))
+
+	log.Info("test info", nil)
+	log.Warning("test warning", nil)
+	log.Error("test error", nil)
+	log.Debug("test debug", nil)
+
+	assert.Equal(t, []string{"test warning", "test error"}, loggerDataMessages(warningLoggerData))
+	assert.Equal(t, []string{"test info", "test warning", "test error", "test debug"}, loggerDataMessages(debugLoggerData))
+}
+
+func TestMultiLogOutputWriteWithFailingOutput(t *testing.T) {
+	var loggerData []*LoggerData
+	errFirst := errors.New("first output failed")
+	errLast := errors.New("last output failed")
+	write := MultiLogOutputWrite(
+		LogOutput{Level: LevelInfo, OutputWriter: func(*LoggerData) error { return errFirst }},
+		LogOutput{Level: LevelInfo, OutputWriter: recordingLoggerDataOutputWrite(&loggerData)},
+		LogOutput{Level: LevelInfo, OutputWriter: func(*LoggerData) error { return errLast }},
+	)
+
+	err := write(&LoggerData{LoggerLevel: LevelInfo, Message: "test"})
+
+	assert.ErrorIs(t, err, errFirst)
+	assert.ErrorIs(t, err, errLast)
+	assert.Equal(t, []string{"test"}, loggerDataMessages(loggerData))
+}
+
+func TestMultiLogOutputWriteWithoutOutputs(t *testing.T) {
+	assert.Nil(t, MultiLogOutputWrite()(&LoggerData{LoggerLevel: LevelInfo}))
+}
+
+func TestMultiLogOutputWriteFromConfigTableDriven(t *testing.T) {
+	type TestCase struct {
+		TestName string
+		Data     config.Logger
+		Expected Level
+	}
+
+	testCases := []TestCase{
+		{
+			TestName: "Unset logger level uses the most verbose output level",
+			Data: config.Logger{
+				Outputs: []config.Output{
+					{Level: string(LevelWarning), OutputWriter: string(cli)},
+					{Level: string(LevelDebug), OutputWriter: string(logfmt)},
+				},
+			},
+			Expected: LevelDebug,
+		},
+		{
+			TestName: "Set logger level is kept",
+			Data: config.Logger{
+				Level: string(LevelWarning),
+				Outputs: []config.Output{
+					{Level: string(LevelDebug), OutputWriter: string(cli)},
+				},
+			},
+			Expected: LevelWarning,
+		},
+		{
+			TestName: "Outputs without level use the default level",
+			Data: config.Logger{
+				Outputs: []config.Output{
+					{OutputWriter: string(cli)},
+					{Level: "undefined", OutputWriter: "undefined"},
+				},
+			},
+			Expected: LevelInfo,
+		},
+	}
+
+	config.Init()
+	config.LoggerConfig = &config.Config{}
+	for _, test := range testCases {
+		t.Run(test.TestName, func(t *testing.T) {
+			config.LoggerConfig.Logger = test.Data
+			log := NewLogInstance()
+			assert.Equal(t, test.Expected, log.loggerLevel)
+			fnName, err := itesting.GetFunctionName(log.outputWrite)
+			if err != nil {
+				t.Fatalf("fatal: could not locate the function %v", err)
+			}
+			assert.Contains(t, fnName, MultiLogOutputWriteName)
+		})
+	}
+}
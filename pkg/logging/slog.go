@@ -0,0 +1,173 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+const slogGroupSeparator = "."
+
+// The slog levels of the logger levels that slog does not define
+const (
+	slogLevelTrace = slog.LevelDebug - 4
+	slogLevelPanic = slog.LevelError + 4
+	slogLevelFatal = slog.LevelError + 8
+)
+
+// A slogHandler is a slog.Handler that registers the slog records using a logger.
+type slogHandler struct {
+	logger *logging
+	fields []Field // the attributes added using WithAttrs, their keys prefixed by their groups
+	prefix string  // the groups opened using WithGroup, e.g. "request."
+}
+
+// NewSlogHandler returns a slog.Handler that registers the slog records using the given logger,
+// so that the code logging through log/slog is written by the outputs of the logger, respecting its level.
+//
+// The slog levels are mapped to LevelDebug, LevelInfo, LevelWarning and LevelError, a level between two of them taking the lower one.
+// The levels below slog.LevelDebug are mapped to LevelTrace, and the levels from slog.LevelError+4 and slog.LevelError+8 to LevelPanic and LevelFatal,
+// the records being registered without panicking nor exiting.
+// The slog attributes are registered as MetaData, in the order they were given, after the attributes added using WithAttrs.
+// The attributes of a group are flattened, their keys prefixed by the group name (e.g. "request.method").
+func NewSlogHandler(l *logging) slog.Handler {
+	return &slogHandler{logger: l}
+}
+
+// Enabled reports whether the logger prints the records of the given level.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.enabled(loggerLevelFromSlog(level))
+}
+
+// Handle registers the record using the logger, with the attributes of the handler and of the record.
+func (h *slogHandler) Handle(_ context.Context, record slog.Record) error {
+	fields := make([]Field, 0, len(h.fields)+record.NumAttrs())
+	fields = append(fields, h.fields...)
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendSlogAttr(fields, h.prefix, attr)
+		return true
+	})
+
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	metaData = withErrorValues(metaData)
+	loggerData := &LoggerData{
+		Timestamp:     record.Time, // the zero time of a record created without time is kept, as required by slog
+		LoggerLevel:   loggerLevelFromSlog(record.Level),
+		Message:       record.Message,
+		MetaData:      metaData,
+		MetaDataOrder: metaDataOrder,
+	}
+	if h.logger.caller {
+		loggerData.Caller = callerFromPC(record.PC) // the code calling the slog.Logger, the skip depth does not apply
+	}
+	h.logger.registerLoggerData(loggerData)
+	return nil
+}
+
+// WithAttrs returns a handler that adds the given attributes to every record, within the groups opened so far.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	derived := *h
+	derived.fields = make([]Field, len(h.fields), len(h.fields)+len(attrs))
+	copy(derived.fields, h.fields)
+	for _, attr := range attrs {
+		derived.fields = appendSlogAttr(derived.fields, h.prefix, attr)
+	}
+	return &derived
+}
+
+// WithGroup returns a handler that prefixes the keys of the attributes added afterwards by the group name.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	derived := *h
+	derived.prefix = h.prefix + name + slogGroupSeparator
+	return &derived
+}
+
+// appendSlogAttr appends the attribute to the fields, its key prefixed by the given groups.
+// The attributes of a group are flattened, and the empty attributes are ignored.
+func appendSlogAttr(fields []Field, prefix string, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + slogGroupSeparator
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			fields = appendSlogAttr(fields, groupPrefix, groupAttr)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: prefix + attr.Key, Value: attr.Value.Any()})
+}
+
+// SlogLogOutputWrite returns an output writer that forwards the logs to the given slog.Handler,
+// e.g. to write the logs using a handler already configured by the application.
+//
+// The log levels are mapped to slog.LevelDebug, slog.LevelInfo, slog.LevelWarn and slog.LevelError,
+// LevelTrace to slog.LevelDebug-4, LevelPanic to slog.LevelError+4 and LevelFatal to slog.LevelError+8,
+// and the MetaData to slog attributes, in the order of MetaDataKeys.
+func SlogLogOutputWrite(handler slog.Handler) LogOutputWriter {
+	return func(loggerData *LoggerData) error {
+		ctx := context.Background()
+		level := slogLevel(loggerData.LoggerLevel)
+		if !handler.Enabled(ctx, level) {
+			return nil
+		}
+
+		record := slog.NewRecord(loggerData.Timestamp, level, loggerData.Message, 0)
+		for _, key := range loggerData.MetaDataKeys() {
+			record.AddAttrs(slog.Any(key, loggerData.MetaData[key]))
+		}
+		if loggerData.Caller != nil {
+			record.AddAttrs(slog.Any(slog.SourceKey, &slog.Source{Function: loggerData.Caller.Function, File: loggerData.Caller.File, Line: loggerData.Caller.Line}))
+		}
+		return handler.Handle(ctx, record)
+	}
+}
+
+// loggerLevelFromSlog converts a slog level to the logger level, a level between two slog levels taking the lower one.
+func loggerLevelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return LevelTrace
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarning
+	case level < slogLevelPanic:
+		return LevelError
+	case level < slogLevelFatal:
+		return LevelPanic
+	default:
+		return LevelFatal
+	}
+}
+
+// slogLevel converts the logger level to a slog level.
+func slogLevel(loggerLevel Level) slog.Level {
+	switch loggerLevel {
+	case LevelTrace:
+		return slogLevelTrace
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarning:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	case LevelPanic:
+		return slogLevelPanic
+	case LevelFatal:
+		return slogLevelFatal
+	default:
+		return slog.LevelInfo
+	}
+}
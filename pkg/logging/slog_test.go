@@ -0,0 +1,150 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slogResult converts a log to the map expected by slogtest, the flattened group keys being nested again.
+func slogResult(loggerData *LoggerData) map[string]any {
+	result := map[string]any{
+		slog.LevelKey:   slogLevel(loggerData.LoggerLevel),
+		slog.MessageKey: loggerData.Message,
+	}
+	if !loggerData.Timestamp.IsZero() {
+		result[slog.TimeKey] = loggerData.Timestamp
+	}
+	for key, value := range loggerData.MetaData {
+		group := result
+		path := strings.Split(key, slogGroupSeparator)
+		for _, name := range path[:len(path)-1] {
+			if _, ok := group[name].(map[string]any); !ok {
+				group[name] = map[string]any{}
+			}
+			group = group[name].(map[string]any)
+		}
+		group[path[len(path)-1]] = value
+	}
+	return result
+}
+
+func TestSlogHandlerConformance(t *testing.T) {
+	var loggerData []*LoggerData
+	slogtest.Run(t, func(t *testing.T) slog.Handler {
+		loggerData = nil
+		return NewSlogHandler(NewLogInstance(WithLoggerLevel(LevelDebug), WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData))))
+	}, func(t *testing.T) map[string]any {
+		if len(loggerData) != 1 {
+			t.Fatalf("fatal: expected a single log, got %d", len(loggerData))
+		}
+		return slogResult(loggerData[0])
+	})
+}
+
+func TestSlogHandler(t *testing.T) {
+	var loggerData []*LoggerData
+	log := NewLogInstance(WithLoggerLevel(LevelWarning), WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)))
+	logger := slog.New(NewSlogHandler(log.With(MetaData{"service": "orders"})))
+
+	logger.With("requestId", "1").WithGroup("request").With("method", "GET").Warn("slow request", "durationMs", 250, slog.Group("user", "id", 2))
+	logger.Debug("hidden")
+
+	assert.Len(t, loggerData, 1)
+	assert.Equal(t, LevelWarning, loggerData[0].LoggerLevel)
+	assert.Equal(t, "slow request", loggerData[0].Message)
+	assert.Equal(t, MetaData{
+		"service":            "orders",
+		"requestId":          "1",
+		"request.method":     "GET",
+		"request.durationMs": int64(250),
+		"request.user.id":    int64(2),
+	}, loggerData[0].MetaData)
+	assert.Equal(t, []string{"requestId", "request.method", "request.durationMs", "request.user.id", "service"}, loggerData[0].MetaDataKeys())
+}
+
+func TestSlogHandlerEnabledTableDriven(t *testing.T) {
+	tests := []struct {
+		loggerLevel Level
+		level       slog.Level
+		expected    bool
+	}{
+		{LevelInfo, slog.LevelDebug, false},
+		{LevelInfo, slog.LevelInfo, true},
+		{LevelInfo, slog.LevelWarn, true},
+		{LevelWarning, slog.LevelInfo, false},
+		{LevelWarning, slog.LevelWarn, true},
+		{LevelWarning, slog.LevelError, true},
+		{LevelError, slog.LevelError, true},
+		{LevelDebug, slog.LevelDebug, true},
+		{LevelDebug, slog.LevelDebug - 4, false},
+		{LevelTrace, slog.LevelDebug - 4, true},
+		{LevelFatal, slog.LevelError, false},
+		{LevelOff, slog.LevelError, false},
+		{LevelOff, slog.LevelError + 8, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.loggerLevel)+"-"+tt.level.String(), func(t *testing.T) {
+			handler := NewSlogHandler(NewLogInstance(WithLoggerLevel(tt.loggerLevel)))
+			assert.Equal(t, tt.expected, handler.Enabled(context.Background(), tt.level))
+		})
+	}
+}
+
+func TestLoggerLevelFromSlogTableDriven(t *testing.T) {
+	tests := []struct {
+		level    slog.Level
+		expected Level
+	}{
+		{slog.LevelDebug - 4, LevelTrace},
+		{slog.LevelDebug - 1, LevelTrace},
+		{slog.LevelDebug, LevelDebug},
+		{slog.LevelInfo, LevelInfo},
+		{slog.LevelInfo + 2, LevelInfo},
+		{slog.LevelWarn, LevelWarning},
+		{slog.LevelError, LevelError},
+		{slog.LevelError + 2, LevelError},
+		{slog.LevelError + 4, LevelPanic},
+		{slog.LevelError + 8, LevelFatal},
+		{slog.LevelError + 12, LevelFatal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			assert.Equal(t, tt.expected, loggerLevelFromSlog(tt.level))
+		})
+	}
+}
+
+func TestSlogLogOutputWrite(t *testing.T) {
+	var buffer bytes.Buffer
+	handler := slog.NewJSONHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelInfo})
+	outputWrite := SlogLogOutputWrite(handler)
+
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := outputWrite(&LoggerData{LoggerLevel: LevelDebug, Timestamp: timestamp, Message: "hidden"})
+	if err != nil {
+		t.Fatalf("fatal: could not write the log %v", err)
+	}
+	metaData, metaDataOrder := fieldsToMetaData([]Field{{"varStr", "string"}, {"varInt", 1}})
+	err = outputWrite(&LoggerData{LoggerLevel: LevelWarning, Timestamp: timestamp, Message: "test", MetaData: metaData, MetaDataOrder: metaDataOrder})
+	if err != nil {
+		t.Fatalf("fatal: could not write the log %v", err)
+	}
+
+	assert.Equal(t, `{"time":"2024-01-02T03:04:05Z","level":"WARN","msg":"test","varStr":"string","varInt":1}`+"\n", buffer.String())
+
+	var record map[string]any
+	if err = json.Unmarshal(buffer.Bytes(), &record); err != nil {
+		t.Fatalf("error: could not decode the slog record %v", err)
+	}
+	assert.Equal(t, "test", record["msg"])
+}
@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"io"
+	"log"
+	"strings"
+)
+
+// A logWriter is an io.Writer that registers every written line as a log of its level.
+type logWriter struct {
+	logger      *logging
+	loggerLevel Level
+}
+
+// Writer returns an io.Writer that registers every written line as a log of the given level, respecting the level of the logger,
+// e.g. to collect the output of the packages writing to an io.Writer.
+// A line split between several writes is registered as several logs.
+//
+// The returned writer is safe to use concurrently.
+func (l *logging) Writer(loggerLevel Level) io.Writer {
+	return &logWriter{logger: l, loggerLevel: loggerLevel}
+}
+
+// StdLogger returns a standard library *log.Logger that registers every printed line as a log of the given level,
+// e.g. to use as the ErrorLog of a net/http.Server.
+// The logger prints neither prefix nor timestamp, the timestamp being added to the log.
+func (l *logging) StdLogger(loggerLevel Level) *log.Logger {
+	return log.New(l.Writer(loggerLevel), "", 0)
+}
+
+// WithStdLogRedirect is a pre-defined "driver" that redirects the output of the standard log package (log.Print, log.Println...)
+// to the created logger, every printed line being registered as a log of the given level.
+// The warnings of the configuration loading are redirected as well.
+//
+// Do not redirect the standard log package to a logger whose output writer prints using it (e.g. SlogLogOutputWrite with the default slog handler).
+func WithStdLogRedirect(loggerLevel Level) func(*logging) {
+	return func(l *logging) {
+		l.stdLogLevel = loggerLevel
+	}
+}
+
+// Write registers every line of p as a log, ignoring the empty lines.
+func (w *logWriter) Write(p []byte) (int, error) {
+	if !w.logger.enabled(w.loggerLevel) {
+		return len(p), nil
+	}
+	for _, line := range strings.Split(string(p), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			continue
+		}
+		w.logger.processLoggerData(w.loggerLevel, line, nil, nil)
+	}
+	return len(p), nil
+}
+
+// redirectStdLog makes the standard log package print to the logger, without prefix nor timestamp.
+func (l *logging) redirectStdLog() {
+	log.SetOutput(l.Writer(l.stdLogLevel))
+	log.SetFlags(0)
+	log.SetPrefix("")
+}
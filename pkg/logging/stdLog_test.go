@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// restoreStdLog restores the output, flags and prefix of the standard log package at the end of the test.
+func restoreStdLog(t *testing.T) {
+	writer, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	t.Cleanup(func() {
+		log.SetOutput(writer)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	})
+}
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name             string
+		loggerLevel      Level
+		writerLevel      Level
+		writes           []string
+		expectedMessages []string
+	}{
+		{
+			name:             "one log per line",
+			loggerLevel:      LevelDebug,
+			writerLevel:      LevelInfo,
+			writes:           []string{"first\nsecond\n"},
+			expectedMessages: []string{"first", "second"},
+		},
+		{
+			name:             "empty lines and carriage returns are ignored",
+			loggerLevel:      LevelDebug,
+			writerLevel:      LevelInfo,
+			writes:           []string{"first\r\n\n\r\n", "second"},
+			expectedMessages: []string{"first", "second"},
+		},
+		{
+			name:             "a line split between writes is several logs",
+			loggerLevel:      LevelDebug,
+			writerLevel:      LevelInfo,
+			writes:           []string{"fir", "st\n"},
+			expectedMessages: []string{"fir", "st"},
+		},
+		{
+			name:             "disabled level",
+			loggerLevel:      LevelInfo,
+			writerLevel:      LevelDebug,
+			writes:           []string{"first\n"},
+			expectedMessages: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var loggerData []*LoggerData
+			logger := NewLogInstance(WithLoggerLevel(test.loggerLevel), WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)))
+			writer := logger.Writer(test.writerLevel)
+			for _, write := range test.writes {
+				n, err := writer.Write([]byte(write))
+				assert.NoError(t, err)
+				assert.Equal(t, len(write), n)
+			}
+
+			var messages []string
+			for _, data := range loggerData {
+				assert.Equal(t, test.writerLevel, data.LoggerLevel)
+				messages = append(messages, data.Message)
+			}
+			assert.Equal(t, test.expectedMessages, messages)
+		})
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var loggerData []*LoggerData
+	logger := NewLogInstance(WithLoggerLevel(LevelError), WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)))
+
+	logger.StdLogger(LevelError).Printf("connection %d refused", 3)
+
+	assert.Len(t, loggerData, 1)
+	assert.Equal(t, LevelError, loggerData[0].LoggerLevel)
+	assert.Equal(t, "connection 3 refused", loggerData[0].Message)
+}
+
+func TestWithStdLogRedirect(t *testing.T) {
+	restoreStdLog(t)
+	log.SetFlags(log.LstdFlags)
+	log.SetPrefix("prefix: ")
+
+	var loggerData []*LoggerData
+	NewLogInstance(WithLoggerLevel(LevelWarning), WithLogOutputWriter(recordingLoggerDataOutputWrite(&loggerData)), WithStdLogRedirect(LevelWarning))
+
+	log.Println("configuration file not found")
+
+	assert.Len(t, loggerData, 1)
+	assert.Equal(t, LevelWarning, loggerData[0].LoggerLevel)
+	assert.Equal(t, "configuration file not found", loggerData[0].Message)
+	assert.Equal(t, 0, log.Flags())
+	assert.Equal(t, "", log.Prefix())
+}
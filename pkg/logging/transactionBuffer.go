@@ -0,0 +1,208 @@
+package logging
+
+// Overflow policies only used by the transaction buffers, see WithTransactionBufferLimit
+const (
+	OverflowFlush OverflowPolicy = "flush" // write the buffered logs of the transaction as a partial chunk, to make room
+)
+
+const (
+	defaultTransactionOverflowPolicy = OverflowDropOldest
+	nonStringValueSize               = 16 // the estimated size of an attribute value that is not a string
+)
+
+// A transactionBufferLimit bounds the logs kept in memory by a transaction, including its sub-transactions.
+// A limit of 0 or lower is unlimited.
+type transactionBufferLimit struct {
+	maxEntries     int
+	maxBytes       int
+	overflowPolicy OverflowPolicy
+}
+
+// A transactionBuffer tracks the logs kept in memory by a transaction, to respect its buffer limit.
+type transactionBuffer struct {
+	transactionBufferLimit
+	entries int
+	bytes   int
+	owners  []*TransactionLoggerData // the data holding every kept log, the oldest first, only tracked to drop the oldest logs
+}
+
+// WithTransactionBufferLimit is a pre-defined "driver" that bounds the logs kept in memory by a transaction, including its sub-transactions,
+// by their number and their estimated size in bytes (the message, the attribute keys and the string attribute values).
+// A limit of 0 or lower is unlimited.
+//
+// When a limit is reached, the overflow policy decides what happens to the logs:
+//   - OverflowDropOldest, the default, discards the oldest logs to make room
+//   - OverflowDropNewest discards the log that is being added
+//   - OverflowFlush writes the logs kept so far as a partial chunk of the transaction, marked as partial, then keeps going
+//
+// A log whose size alone exceeds the maximum size is discarded whatever the overflow policy.
+// The number of discarded logs is written with the transaction.
+func WithTransactionBufferLimit(maxEntries int, maxBytes int, overflowPolicy OverflowPolicy) func(*transactionLogging) {
+	return func(l *transactionLogging) {
+		l.bufferLimit = newTransactionBufferLimit(maxEntries, maxBytes, overflowPolicy)
+	}
+}
+
+// newTransactionBufferLimit creates a buffer limit, falling back to the default overflow policy if the given one is not supported.
+func newTransactionBufferLimit(maxEntries int, maxBytes int, overflowPolicy OverflowPolicy) transactionBufferLimit {
+	switch overflowPolicy {
+	case OverflowDropOldest, OverflowDropNewest, OverflowFlush:
+	default:
+		overflowPolicy = defaultTransactionOverflowPolicy
+	}
+	return transactionBufferLimit{
+		maxEntries:     maxEntries,
+		maxBytes:       maxBytes,
+		overflowPolicy: overflowPolicy,
+	}
+}
+
+// bufferLog adds the log to the given data of the transaction, respecting the buffer limit of the transaction.
+// With OverflowFlush, it returns the partial chunk to write once the transaction lock is released, nil otherwise.
+//
+// bufferLog must be called while holding the lock of the transaction.
+func (s *transactionState) bufferLog(data *TransactionLoggerData, log *LoggerData) *TransactionLoggerData {
+	size := loggerDataSize(log)
+	var chunk *TransactionLoggerData
+
+	if s.buffer.exceeded(size) {
+		if s.buffer.maxBytes > 0 && size > s.buffer.maxBytes {
+			// the log alone exceeds the limit, making room would not help
+			s.data.DroppedLogs++
+			return nil
+		}
+		switch s.buffer.overflowPolicy {
+		case OverflowDropNewest:
+			s.data.DroppedLogs++
+			return nil
+		case OverflowDropOldest:
+			for s.buffer.entries > 0 && s.buffer.exceeded(size) {
+				s.dropOldestLog()
+			}
+		case OverflowFlush:
+			if s.buffer.entries > 0 {
+				chunk = s.detachChunk()
+			}
+		}
+	}
+
+	data.TransactionLogs = append(data.TransactionLogs, log)
+	s.buffer.entries++
+	s.buffer.bytes += size
+	if s.buffer.overflowPolicy == OverflowDropOldest && s.buffer.limited() {
+		s.buffer.owners = append(s.buffer.owners, data)
+	}
+	return chunk
+}
+
+// limited reports whether the buffer has a limit.
+func (b *transactionBuffer) limited() bool {
+	return b.maxEntries > 0 || b.maxBytes > 0
+}
+
+// exceeded reports whether adding a log of the given size exceeds the limit of the buffer.
+func (b *transactionBuffer) exceeded(size int) bool {
+	return (b.maxEntries > 0 && b.entries+1 > b.maxEntries) || (b.maxBytes > 0 && b.bytes+size > b.maxBytes)
+}
+
+// dropOldestLog discards the oldest log kept by the transaction.
+func (s *transactionState) dropOldestLog() {
+	owner := s.buffer.owners[0]
+	s.buffer.owners[0] = nil
+	s.buffer.owners = s.buffer.owners[1:]
+
+	log := owner.TransactionLogs[0]
+	owner.TransactionLogs[0] = nil
+	owner.TransactionLogs = owner.TransactionLogs[1:]
+
+	s.buffer.entries--
+	s.buffer.bytes -= loggerDataSize(log)
+	s.data.DroppedLogs++
+}
+
+// detachChunk moves the logs kept by the transaction to a partial chunk.
+// If the transaction has a flush level that was not reached yet, the logs are discarded and no chunk is returned.
+func (s *transactionState) detachChunk() *TransactionLoggerData {
+	chunk := detachTransactionLoggerData(s.data)
+	s.buffer.entries = 0
+	s.buffer.bytes = 0
+
+	if s.flushLevel != "" && !s.triggered {
+		s.data.DroppedLogs += countTransactionLogs(chunk)
+		return nil
+	}
+	chunk.Partial = true
+	return chunk
+}
+
+// detachTransactionLoggerData moves the logs of the transaction and of its sub-transactions to a copy, that can be written while the transaction goes on.
+// The ended sub-transactions are moved to the copy, unless they have open sub-transactions.
+// The open sub-transactions are copied without an end timestamp and marked as partial, as they go on in the next chunks.
+func detachTransactionLoggerData(transactionLoggerData *TransactionLoggerData) *TransactionLoggerData {
+	chunk := &TransactionLoggerData{
+		LoggerLevel:     transactionLoggerData.LoggerLevel,
+		TransactionLogs: transactionLoggerData.TransactionLogs,
+	}
+	transactionLoggerData.TransactionLogs = []*LoggerData{}
+
+	var openSubTransactions []*SubTransactionLoggerData
+	for _, subTransaction := range transactionLoggerData.SubTransactions {
+		if !subTransaction.open() {
+			chunk.SubTransactions = append(chunk.SubTransactions, subTransaction)
+			continue
+		}
+
+		detached := &SubTransactionLoggerData{
+			TransactionID:         subTransaction.TransactionID,
+			StartTimestamp:        subTransaction.StartTimestamp,
+			EndTimestamp:          subTransaction.EndTimestamp,
+			TransactionLoggerData: *detachTransactionLoggerData(&subTransaction.TransactionLoggerData),
+		}
+		detached.Partial = detached.EndTimestamp.IsZero()
+		detached.Status = subTransaction.Status
+		detached.StatusMessage = subTransaction.StatusMessage
+		chunk.SubTransactions = append(chunk.SubTransactions, detached)
+		openSubTransactions = append(openSubTransactions, subTransaction)
+	}
+	transactionLoggerData.SubTransactions = openSubTransactions
+	return chunk
+}
+
+// open reports whether the sub-transaction, or one of its own sub-transactions, was not stopped.
+func (subTransaction *SubTransactionLoggerData) open() bool {
+	if subTransaction.EndTimestamp.IsZero() {
+		return true
+	}
+	for _, nested := range subTransaction.SubTransactions {
+		if nested.open() {
+			return true
+		}
+	}
+	return false
+}
+
+// countTransactionLogs returns the number of logs of the transaction, including its sub-transactions.
+func countTransactionLogs(transactionLoggerData *TransactionLoggerData) int {
+	count := len(transactionLoggerData.TransactionLogs)
+	for _, subTransaction := range transactionLoggerData.SubTransactions {
+		count += countTransactionLogs(&subTransaction.TransactionLoggerData)
+	}
+	return count
+}
+
+// loggerDataSize estimates the size in bytes of a log, using its message, its attribute keys and its string attribute values.
+func loggerDataSize(loggerData *LoggerData) int {
+	size := len(loggerData.Message)
+	for key, value := range loggerData.MetaData {
+		size += len(key)
+		if str, ok := value.(string); ok {
+			size += len(str)
+		} else {
+			size += nonStringValueSize
+		}
+	}
+	if loggerData.Caller != nil {
+		size += len(loggerData.Caller.File) + len(loggerData.Caller.Function) + nonStringValueSize
+	}
+	return size
+}
@@ -0,0 +1,284 @@
+package logging
+
+import (
+	"encoding/json"
+	"go-telemetry/pkg/internal/config"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTransactionBufferLimit(t *testing.T) {
+	l := transactionLogging{}
+	WithTransactionBufferLimit(10, 1024, OverflowFlush)(&l)
+	assert.Equal(t, transactionBufferLimit{maxEntries: 10, maxBytes: 1024, overflowPolicy: OverflowFlush}, l.bufferLimit)
+
+	WithTransactionBufferLimit(10, 0, OverflowBlock)(&l)
+	assert.Equal(t, OverflowDropOldest, l.bufferLimit.overflowPolicy)
+}
+
+func TestNewTransactionLogWithBufferLimitConfig(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	config.Init()
+	config.LoggerConfig = &config.Config{TransactionLogger: config.TransactionLogger{MaxEntries: 100, MaxBytes: 2048, OverflowPolicy: string(OverflowDropNewest)}}
+	t.Cleanup(func() {
+		config.LoggerConfig = &config.Config{}
+	})
+
+	log, err := NewTransactionLog(testTransactionId)
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Equal(t, transactionBufferLimit{maxEntries: 100, maxBytes: 2048, overflowPolicy: OverflowDropNewest}, log.bufferLimit)
+}
+
+func TestTransactionBufferLimitTableDriven(t *testing.T) {
+	tests := []struct {
+		name                string
+		maxEntries          int
+		maxBytes            int
+		overflowPolicy      OverflowPolicy
+		messages            []string
+		expectedMessages    []string
+		expectedDroppedLogs int
+	}{
+		{
+			name:             "unlimited",
+			overflowPolicy:   OverflowDropOldest,
+			messages:         []string{"1", "2", "3"},
+			expectedMessages: []string{"1", "2", "3"},
+		},
+		{
+			name:                "drop oldest by entries",
+			maxEntries:          2,
+			overflowPolicy:      OverflowDropOldest,
+			messages:            []string{"1", "2", "3", "4"},
+			expectedMessages:    []string{"3", "4"},
+			expectedDroppedLogs: 2,
+		},
+		{
+			name:                "drop newest by entries",
+			maxEntries:          2,
+			overflowPolicy:      OverflowDropNewest,
+			messages:            []string{"1", "2", "3", "4"},
+			expectedMessages:    []string{"1", "2"},
+			expectedDroppedLogs: 2,
+		},
+		{
+			name:                "drop oldest by bytes",
+			maxBytes:            10,
+			overflowPolicy:      OverflowDropOldest,
+			messages:            []string{"aaaaa", "bbbbb", "ccccc"},
+			expectedMessages:    []string{"bbbbb", "ccccc"},
+			expectedDroppedLogs: 1,
+		},
+		{
+			name:                "drop a log larger than the limit",
+			maxBytes:            4,
+			overflowPolicy:      OverflowDropOldest,
+			messages:            []string{"aa", "bbbbb"},
+			expectedMessages:    []string{"aa"},
+			expectedDroppedLogs: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactionLoggerOnce = sync.Once{}
+			var transactions []*TransactionLoggerData
+			log, err := NewTransactionLog(testTransactionId, WithTransactionBufferLimit(tt.maxEntries, tt.maxBytes, tt.overflowPolicy), WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+			if err != nil {
+				t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+			}
+
+			err = log.StartTransactionLogging()
+			if err != nil {
+				t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+			}
+			for _, message := range tt.messages {
+				log.Info(message, nil)
+			}
+			err = log.StopTransactionLogging()
+			if err != nil {
+				t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+			}
+
+			assert.Len(t, transactions, 1)
+			transactionLoggerData := transactions[0]
+			assert.Equal(t, tt.expectedMessages, transactionLogMessages(transactionLoggerData))
+			assert.Equal(t, tt.expectedDroppedLogs, transactionLoggerData.DroppedLogs)
+			assert.False(t, transactionLoggerData.Partial)
+		})
+	}
+}
+
+func TestTransactionBufferLimitDropOldestWithSubTransactions(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var transactions []*TransactionLoggerData
+	log, err := NewTransactionLog(testTransactionId, WithTransactionBufferLimit(2, 0, OverflowDropOldest), WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	log.Info("1", nil)
+	subLog, err := log.StartSubTransaction("payment")
+	if err != nil {
+		t.Fatalf("fatal: sub-transaction could not be started for transaction %s %v", testTransactionId, err)
+	}
+	subLog.Info("2", nil)
+	log.Info("3", nil)
+	err = log.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Len(t, transactions, 1)
+	transactionLoggerData := transactions[0]
+	assert.Equal(t, []string{"3"}, transactionLogMessages(transactionLoggerData))
+	assert.Equal(t, []string{"2"}, transactionLogMessages(&transactionLoggerData.SubTransactions[0].TransactionLoggerData))
+	assert.Equal(t, 1, transactionLoggerData.DroppedLogs)
+}
+
+func TestTransactionBufferLimitFlush(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var chunks []*TransactionLoggerData
+	log, err := NewTransactionLog(testTransactionId, WithTransactionBufferLimit(2, 0, OverflowFlush), WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&chunks)))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	log.Info("1", nil)
+	subLog, err := log.StartSubTransaction("payment")
+	if err != nil {
+		t.Fatalf("fatal: sub-transaction could not be started for transaction %s %v", testTransactionId, err)
+	}
+	subLog.Info("2", nil)
+	log.Info("3", nil)
+	subLog.Info("4", nil)
+	err = log.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Len(t, chunks, 2)
+
+	assert.True(t, chunks[0].Partial)
+	assert.Empty(t, chunks[0].Status)
+	assert.Equal(t, []string{"1"}, transactionLogMessages(chunks[0]))
+	assert.Equal(t, []string{"2"}, transactionLogMessages(&chunks[0].SubTransactions[0].TransactionLoggerData))
+	assert.True(t, chunks[0].SubTransactions[0].EndTimestamp.IsZero())
+	assert.True(t, chunks[0].SubTransactions[0].Partial)
+
+	assert.False(t, chunks[1].Partial)
+	assert.Equal(t, TransactionStatusOk, chunks[1].Status)
+	assert.Equal(t, []string{"3"}, transactionLogMessages(chunks[1]))
+	assert.Equal(t, []string{"4"}, transactionLogMessages(&chunks[1].SubTransactions[0].TransactionLoggerData))
+	assert.False(t, chunks[1].SubTransactions[0].EndTimestamp.IsZero())
+	assert.False(t, chunks[1].SubTransactions[0].Partial)
+	assert.Equal(t, 0, chunks[1].DroppedLogs)
+}
+
+func TestTransactionBufferLimitFlushOpenSubTransactionOutput(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	chunk := &TransactionLoggerData{
+		LoggerLevel: LevelInfo,
+		Partial:     true,
+		SubTransactions: []*SubTransactionLoggerData{
+			{
+				TransactionID:  "payment",
+				StartTimestamp: ts,
+				TransactionLoggerData: TransactionLoggerData{
+					LoggerLevel:     LevelInfo,
+					TransactionLogs: []*LoggerData{{LoggerLevel: LevelInfo, Timestamp: ts, Message: "charged"}},
+					Partial:         true,
+				},
+			},
+		},
+	}
+
+	text := formatTransactionLoggerData(testTransactionId, ts, ts.Add(time.Second), chunk)
+	assert.Contains(t, text, "Sub-transaction {payment} started!")
+	assert.NotContains(t, text, "Sub-transaction {payment} ended!")
+	assert.NotContains(t, formatLogfmtTransactionLoggerData(testTransactionId, ts, ts.Add(time.Second), chunk), "sub-transaction ended")
+
+	b, err := json.Marshal(chunk.SubTransactions[0])
+	if err != nil {
+		t.Fatalf("fatal: could not encode the sub-transaction %v", err)
+	}
+	assert.Contains(t, string(b), `"partial":true`)
+	assert.NotContains(t, string(b), "endTimestamp")
+	assert.NotContains(t, string(b), "durationMs")
+}
+
+func TestTransactionBufferLimitFlushWithFlushLevel(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var chunks []*TransactionLoggerData
+	log, err := NewTransactionLog(testTransactionId, WithTransactionBufferLimit(2, 0, OverflowFlush), WithTransactionFlushLevel(LevelError), WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&chunks)))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	log.Debug("1", nil)
+	log.Debug("2", nil)
+	log.Error("3", nil)
+	log.Debug("4", nil)
+	log.Debug("5", nil)
+	err = log.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	// the first chunk is discarded, since the flush level was not reached yet
+	assert.Len(t, chunks, 2)
+	assert.Equal(t, []string{"3", "4"}, transactionLogMessages(chunks[0]))
+	assert.True(t, chunks[0].Partial)
+	assert.Equal(t, []string{"5"}, transactionLogMessages(chunks[1]))
+	assert.Equal(t, 2, chunks[1].DroppedLogs)
+}
+
+func TestTransactionBufferLimitFlushOversizedLog(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var chunks []*TransactionLoggerData
+	log, err := NewTransactionLog(testTransactionId, WithTransactionBufferLimit(0, 10, OverflowFlush), WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&chunks)))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	log.Info("a message longer than the limit", nil)
+	log.Info("1", nil)
+	log.Info("a message longer than the limit", nil)
+	err = log.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	// no empty partial chunk is written for the oversized logs
+	assert.Len(t, chunks, 1)
+	assert.False(t, chunks[0].Partial)
+	assert.Equal(t, []string{"1"}, transactionLogMessages(chunks[0]))
+	assert.Equal(t, 2, chunks[0].DroppedLogs)
+}
+
+func TestLoggerDataSize(t *testing.T) {
+	loggerData := &LoggerData{Message: "test", MetaData: MetaData{"key": "value", "count": 1}}
+	assert.Equal(t, len("test")+len("key")+len("value")+len("count")+nonStringValueSize, loggerDataSize(loggerData))
+}
@@ -0,0 +1,37 @@
+package logging
+
+import "context"
+
+// A transactionContextKey is the key of the transaction logger stored in a context.
+type transactionContextKey struct{}
+
+// StartTransaction creates a transaction logging instance, using NewTransactionLog, starts the transaction
+// and returns a copy of the context that holds it, so that any code with the context can add logs to the transaction using FromContext.
+//
+// If the transaction could not be created or started, the given context is returned with the error.
+//
+// ! Call StopTransactionLogging when logging is finished.
+func StartTransaction(ctx context.Context, transactionId string, options ...func(*transactionLogging)) (context.Context, *transactionLogging, error) {
+	l, err := NewTransactionLog(transactionId, options...)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	err = l.StartTransactionLogging()
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	return context.WithValue(ctx, transactionContextKey{}, l), l, nil
+}
+
+// FromContext returns the transaction logger stored in the context by StartTransaction.
+//
+// If the context holds no transaction, a no-op logger is returned, that discards every log,
+// so the result can always be used without checking it.
+func FromContext(ctx context.Context) *transactionLogging {
+	if l, ok := ctx.Value(transactionContextKey{}).(*transactionLogging); ok && l != nil {
+		return l
+	}
+	return &transactionLogging{loggerLevel: LevelOff}
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartTransaction(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var transactions []*TransactionLoggerData
+	ctx, log, err := StartTransaction(context.Background(), testTransactionId, WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Same(t, log, FromContext(ctx))
+	_, found := availableTransactions.Load(testTransactionId)
+	assert.True(t, found)
+
+	FromContext(ctx).Info("test", nil)
+	err = FromContext(ctx).StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Len(t, transactions, 1)
+	assert.Equal(t, []string{"test"}, transactionLogMessages(transactions[0]))
+}
+
+func TestStartTransactionAlreadyStarted(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	_, log, err := StartTransaction(context.Background(), testTransactionId)
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	t.Cleanup(func() {
+		err := log.StopTransactionLogging()
+		if err != nil {
+			t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+		}
+	})
+
+	parentCtx := context.Background()
+	ctx, duplicateLog, err := StartTransaction(parentCtx, testTransactionId)
+
+	assert.Nil(t, duplicateLog)
+	assert.Equal(t, parentCtx, ctx)
+	assert.Equal(t, fmt.Sprintf("error: the provided transaction was already started %s", testTransactionId), err.Error())
+}
+
+func TestFromContextWithoutTransaction(t *testing.T) {
+	log := FromContext(context.Background())
+
+	assert.NotNil(t, log)
+	assert.Equal(t, LevelOff, log.loggerLevel)
+
+	// the no-op logger discards every log
+	log.With(map[string]any{"requestId": "1"}).Info("test", nil)
+	assert.Nil(t, log.StartTransactionLogging())
+	assert.Nil(t, log.StopTransactionLogging())
+}
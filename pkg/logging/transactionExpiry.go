@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// A OpenTransaction describes a transaction that was started and not yet stopped.
+type OpenTransaction struct {
+	TransactionID  string
+	StartTimestamp time.Time
+	Age            time.Duration
+}
+
+// WithTransactionMaxLifetime is a pre-defined "driver" that specifies how long a transaction can stay open.
+// Once the maximum lifetime is exceeded, the transaction is stopped and written by a background timer, with TransactionStatusTimedOut,
+// so that the transactions that are never stopped (e.g. after a panic) do not leak.
+// A maximum lifetime of 0 or lower disables the expiry.
+func WithTransactionMaxLifetime(maxLifetime time.Duration) func(*transactionLogging) {
+	return func(l *transactionLogging) {
+		l.maxLifetime = maxLifetime
+	}
+}
+
+// expire stops and writes the given transaction, marked as timed out, once it exceeded its maximum lifetime.
+// If the transaction was stopped meanwhile, nothing is done.
+func (l *transactionLogging) expire(state *transactionState) {
+	err := state.update(nil, func(data *TransactionLoggerData) {
+		data.Status = TransactionStatusTimedOut
+		data.StatusMessage = fmt.Sprintf("the transaction exceeded its maximum lifetime of %s", l.maxLifetime)
+	})
+	if err != nil {
+		return
+	}
+	// the output errors are printed by stopTransaction
+	_ = l.stopTransaction(state)
+}
+
+// OpenTransactions returns the transactions that were started and not yet stopped, the oldest first,
+// e.g. to find the transactions that are never stopped.
+//
+// OpenTransactions is safe to call concurrently with other operations.
+func OpenTransactions() []OpenTransaction {
+	if availableTransactions == nil {
+		return nil
+	}
+
+	now := time.Now()
+	var openTransactions []OpenTransaction
+	availableTransactions.Range(func(_, value any) bool {
+		state, ok := value.(*transactionState)
+		if !ok {
+			return true
+		}
+		openTransactions = append(openTransactions, OpenTransaction{
+			TransactionID:  state.transactionId,
+			StartTimestamp: state.startTimestamp,
+			Age:            now.Sub(state.startTimestamp),
+		})
+		return true
+	})
+	sort.Slice(openTransactions, func(i, j int) bool {
+		return openTransactions[i].StartTimestamp.Before(openTransactions[j].StartTimestamp)
+	})
+	return openTransactions
+}
@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTransactionMaxLifetime(t *testing.T) {
+	l := transactionLogging{}
+	WithTransactionMaxLifetime(time.Minute)(&l)
+	assert.Equal(t, time.Minute, l.maxLifetime)
+}
+
+func TestTransactionExpiry(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var transactions []*TransactionLoggerData
+	log, err := NewTransactionLog(testTransactionId, WithTransactionMaxLifetime(10*time.Millisecond), WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	log.Info("test", nil)
+	_, err = log.StartSubTransaction("payment")
+	if err != nil {
+		t.Fatalf("fatal: sub-transaction could not be started for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Eventually(t, func() bool {
+		recordedMutex.Lock()
+		defer recordedMutex.Unlock()
+		return len(transactions) == 1
+	}, time.Second, time.Millisecond)
+	recordedMutex.Lock()
+	defer recordedMutex.Unlock()
+	assert.Len(t, transactions, 1)
+	transactionLoggerData := transactions[0]
+
+	assert.Equal(t, TransactionStatusTimedOut, transactionLoggerData.Status)
+	assert.Equal(t, "the transaction exceeded its maximum lifetime of 10ms", transactionLoggerData.StatusMessage)
+	assert.Len(t, transactionLoggerData.TransactionLogs, 1)
+	assert.Equal(t, TransactionStatusTimedOut, transactionLoggerData.SubTransactions[0].Status)
+	assert.Empty(t, OpenTransactions())
+
+	err = log.StopTransactionLogging()
+	assert.Equal(t, fmt.Sprintf("error: the provided transaction was not started or was recently ended %s", testTransactionId), err.Error())
+}
+
+func TestTransactionStoppedBeforeExpiry(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var transactions []*TransactionLoggerData
+	log, err := NewTransactionLog(testTransactionId, WithTransactionMaxLifetime(10*time.Millisecond), WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	err = log.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	recordedMutex.Lock()
+	defer recordedMutex.Unlock()
+	assert.Len(t, transactions, 1)
+	assert.Equal(t, TransactionStatusOk, transactions[0].Status)
+}
+
+func TestOpenTransactions(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var logs []*transactionLogging
+	for _, transactionId := range []string{"first", "second"} {
+		log, err := NewTransactionLog(transactionId, WithTransactionLogOutputWriter(func(string, time.Time, time.Time, *TransactionLoggerData) error {
+			return nil
+		}))
+		if err != nil {
+			t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", transactionId, err)
+		}
+		err = log.StartTransactionLogging()
+		if err != nil {
+			t.Fatalf("fatal: transaction log could not be started for transaction %s %v", transactionId, err)
+		}
+		logs = append(logs, log)
+		time.Sleep(time.Millisecond)
+	}
+	logs[0].Info("test", nil)
+
+	openTransactions := OpenTransactions()
+
+	assert.Len(t, openTransactions, 2)
+	assert.Equal(t, "first", openTransactions[0].TransactionID)
+	assert.Equal(t, logs[0].startTimestamp, openTransactions[0].StartTimestamp)
+	assert.Equal(t, "second", openTransactions[1].TransactionID)
+	assert.Greater(t, openTransactions[0].Age, openTransactions[1].Age)
+
+	err := logs[0].StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped %v", err)
+	}
+	openTransactions = OpenTransactions()
+	assert.Len(t, openTransactions, 1)
+	assert.Equal(t, "second", openTransactions[0].TransactionID)
+
+	err = logs[1].StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped %v", err)
+	}
+	assert.Empty(t, OpenTransactions())
+}
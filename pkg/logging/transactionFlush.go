@@ -0,0 +1,26 @@
+package logging
+
+// WithTransactionFlushLevel is a pre-defined "driver" that specifies the level a log must reach for the transaction to be written
+// ("fingers-crossed" logging): the logs of every level, including Trace and Debug, are kept in the transaction, and once stopped,
+// the transaction is written with all its logs if one of them, or of its sub-transactions, is at least as severe as the flush level.
+// Otherwise the transaction is discarded.
+//
+// The severity increases from Trace to Debug, Info, Warning, Error, Panic and Fatal. A flush level of LevelOff, the default, writes every transaction
+// with the logs allowed by the transaction log level.
+func WithTransactionFlushLevel(flushLevel Level) func(*transactionLogging) {
+	return func(l *transactionLogging) {
+		l.flushLevel = flushLevel
+	}
+}
+
+// flushLevelEnabled reports whether the transaction is only written if a log reached the flush level.
+func (l *transactionLogging) flushLevelEnabled() bool {
+	return l.flushLevel != "" && l.flushLevel != LevelOff
+}
+
+// discarded reports whether the stopped transaction must not be written, since none of its logs reached the flush level.
+func (s *transactionState) discarded() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.flushLevel != "" && !s.triggered
+}
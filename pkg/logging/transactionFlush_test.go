@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"go-telemetry/pkg/internal/config"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTransactionFlushLevel(t *testing.T) {
+	l := transactionLogging{}
+	WithTransactionFlushLevel(LevelError)(&l)
+	assert.Equal(t, LevelError, l.flushLevel)
+}
+
+func TestNewTransactionLogWithFlushLevelConfig(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	config.Init()
+	config.LoggerConfig = &config.Config{TransactionLogger: config.TransactionLogger{FlushLevel: string(LevelWarning)}}
+	t.Cleanup(func() {
+		config.LoggerConfig = &config.Config{}
+	})
+
+	log, err := NewTransactionLog(testTransactionId)
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Equal(t, LevelWarning, log.flushLevel)
+}
+
+func TestTransactionFlushLevelTableDriven(t *testing.T) {
+	tests := []struct {
+		name             string
+		flushLevel       Level
+		log              func(log *transactionLogging)
+		expectedMessages []string // nil if the transaction is discarded
+	}{
+		{
+			name:       "discarded without error",
+			flushLevel: LevelError,
+			log: func(log *transactionLogging) {
+				log.Debug("test debug", nil)
+				log.Warning("test warning", nil)
+			},
+			expectedMessages: nil,
+		},
+		{
+			name:       "written with every level on error",
+			flushLevel: LevelError,
+			log: func(log *transactionLogging) {
+				log.Debug("test debug", nil)
+				log.Info("test info", nil)
+				log.Error("test error", nil)
+			},
+			expectedMessages: []string{"test debug", "test info", "test error"},
+		},
+		{
+			name:       "written on a more severe level",
+			flushLevel: LevelWarning,
+			log: func(log *transactionLogging) {
+				log.Debug("test debug", nil)
+				log.Error("test error", nil)
+			},
+			expectedMessages: []string{"test debug", "test error"},
+		},
+		{
+			name:       "written on error in a sub-transaction",
+			flushLevel: LevelError,
+			log: func(log *transactionLogging) {
+				log.Debug("test debug", nil)
+				subLog, err := log.StartSubTransaction("payment")
+				if err != nil {
+					t.Fatalf("fatal: sub-transaction could not be started for transaction %s %v", testTransactionId, err)
+				}
+				subLog.Error("test error", nil)
+			},
+			expectedMessages: []string{"test debug"},
+		},
+		{
+			name:       "always written with flush level off",
+			flushLevel: LevelOff,
+			log: func(log *transactionLogging) {
+				log.Debug("test debug", nil)
+				log.Info("test info", nil)
+			},
+			expectedMessages: []string{"test info"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactionLoggerOnce = sync.Once{}
+			var transactions []*TransactionLoggerData
+			log, err := NewTransactionLog(testTransactionId, WithTransactionLoggerLevel(LevelInfo), WithTransactionFlushLevel(tt.flushLevel), WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+			if err != nil {
+				t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+			}
+
+			err = log.StartTransactionLogging()
+			if err != nil {
+				t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+			}
+			tt.log(log)
+			err = log.StopTransactionLogging()
+			if err != nil {
+				t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+			}
+
+			if tt.expectedMessages == nil {
+				assert.Empty(t, transactions)
+				return
+			}
+			assert.Len(t, transactions, 1)
+			assert.Equal(t, tt.expectedMessages, transactionLogMessages(transactions[0]))
+		})
+	}
+}
@@ -3,134 +3,153 @@ package logging
 import (
 	"encoding/json"
 	"fmt"
-	"go-telemetry/pkg/internal/config"
 	"io"
 	"os"
-	"path/filepath"
-	"reflect"
-	"strings"
 	"time"
 )
 
 // A TransactionLogOutputWriter is a output writer function for transaction logging.
 type TransactionLogOutputWriter func(transactionId string, startTimestamp time.Time, endTimestamp time.Time, transactionLoggerData *TransactionLoggerData) error
 
+// A transactionOutputJSON is the JSON representation of a transaction, used by the JSON writers.
+type transactionOutputJSON struct {
+	TransactionID   string                 `json:"transactionId"`
+	StartTimestamp  time.Time              `json:"startTimestamp"`
+	EndTimestamp    time.Time              `json:"endTimestamp"`
+	DurationMs      float64                `json:"durationMs"`
+	Status          TransactionStatus      `json:"status,omitempty"`
+	StatusMessage   string                 `json:"statusMessage,omitempty"`
+	DroppedLogs     int                    `json:"droppedLogs,omitempty"`
+	Partial         bool                   `json:"partial,omitempty"`
+	TransactionLogs *TransactionLoggerData `json:"transactionData"`
+}
+
+// newTransactionOutputJSON creates the JSON representation of a transaction, with its computed duration.
+func newTransactionOutputJSON(transactionId string, startTimestamp time.Time, endTimestamp time.Time, transactionLoggerData *TransactionLoggerData) *transactionOutputJSON {
+	return &transactionOutputJSON{
+		TransactionID:   transactionId,
+		StartTimestamp:  startTimestamp,
+		EndTimestamp:    endTimestamp,
+		DurationMs:      durationMs(endTimestamp.Sub(startTimestamp)),
+		Status:          transactionLoggerData.Status,
+		StatusMessage:   transactionLoggerData.StatusMessage,
+		DroppedLogs:     transactionLoggerData.DroppedLogs,
+		Partial:         transactionLoggerData.Partial,
+		TransactionLogs: transactionLoggerData,
+	}
+}
+
+// MarshalJSON encodes the sub-transaction with its status and its computed duration, next to its timestamps.
+// A sub-transaction that goes on in the next chunks of a partial transaction is marked as partial, without an end timestamp nor a duration.
+func (subTransaction *SubTransactionLoggerData) MarshalJSON() ([]byte, error) {
+	type subTransactionLoggerData SubTransactionLoggerData // without the MarshalJSON method
+	output := struct {
+		*subTransactionLoggerData
+		EndTimestamp  *time.Time        `json:"endTimestamp,omitempty"`
+		DurationMs    *float64          `json:"durationMs,omitempty"`
+		Status        TransactionStatus `json:"status,omitempty"`
+		StatusMessage string            `json:"statusMessage,omitempty"`
+		Partial       bool              `json:"partial,omitempty"`
+	}{
+		subTransactionLoggerData: (*subTransactionLoggerData)(subTransaction),
+		Status:                   subTransaction.Status,
+		StatusMessage:            subTransaction.StatusMessage,
+		Partial:                  subTransaction.Partial,
+	}
+	if !subTransaction.EndTimestamp.IsZero() {
+		duration := durationMs(subTransaction.EndTimestamp.Sub(subTransaction.StartTimestamp))
+		output.EndTimestamp = &subTransaction.EndTimestamp
+		output.DurationMs = &duration
+	}
+	return json.Marshal(&output)
+}
+
+// durationMs converts a duration to fractional milliseconds.
+func durationMs(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
 // CLITransactionLogOutputWrite returns an output writer that prints the transaction log to the CLI.
 func CLITransactionLogOutputWrite() TransactionLogOutputWriter {
 	return func(transactionId string, startTimestamp time.Time, endTimestamp time.Time, transactionLoggerData *TransactionLoggerData) error {
-		fmt.Printf("[%s] Transaction {%s} started!\n", startTimestamp.Format(timestampFormat), transactionId)
-
-		for _, entry := range transactionLoggerData.TransactionLogs {
-			fmt.Printf("--> [%s] [%s] %s", entry.Timestamp.Format(timestampFormat), entry.LoggerLevel, entry.Message)
-			for k, v := range entry.MetaData {
-				typeName := reflect.TypeOf(v).Name()
-				if strings.Contains(typeName, "int") {
-					fmt.Printf(" [%s=%d]", k, v)
-				} else if strings.Contains(typeName, "float") {
-					fmt.Printf(" [%s=%f]", k, v)
-				} else if typeName == "string" {
-					fmt.Printf(" [%s=%s]", k, v)
-				} else {
-					fmt.Printf("\n")
-					return fmt.Errorf("error: formatting for this variable type is not implement %s", typeName)
-				}
-			}
-			fmt.Printf("\n")
+		_, err := fmt.Print(formatTransactionLoggerData(transactionId, startTimestamp, endTimestamp, transactionLoggerData))
+		if err != nil {
+			return fmt.Errorf("error: could not write to the CLI %v", err)
 		}
-
-		fmt.Printf("[%s] Transaction {%s} ended!\n", endTimestamp.Format(timestampFormat), transactionId)
 		return nil
 	}
 }
 
-// JSONTransactionLogOutputFileWrite returns an output writer that prints the transaction log to a JSON file.
-func JSONTransactionLogOutputFileWrite() TransactionLogOutputWriter {
+// LogfmtTransactionLogOutputWrite returns an output writer that prints the transaction log to the CLI, in logfmt format.
+// Every line holds the transaction id, the transaction start and end being printed as separate lines.
+func LogfmtTransactionLogOutputWrite() TransactionLogOutputWriter {
 	return func(transactionId string, startTimestamp time.Time, endTimestamp time.Time, transactionLoggerData *TransactionLoggerData) error {
-		fileName := fmt.Sprintf(filepath.Join(config.LoggerConfig.Logger.OutputDir, "%s_transactions.json"), time.Now().Format(fileTimestampFormat))
-		f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE, 0644)
+		_, err := fmt.Print(formatLogfmtTransactionLoggerData(transactionId, startTimestamp, endTimestamp, transactionLoggerData))
 		if err != nil {
-			return fmt.Errorf("error: could not open json file %v", err)
+			return fmt.Errorf("error: could not write to the CLI %v", err)
 		}
-		defer f.Close()
+		return nil
+	}
+}
 
-		ret, err := f.Seek(-int64(len([]byte(endArray))), io.SeekEnd)
-		if err != nil {
-			_, err := f.Write([]byte(startArray))
+// JSONTransactionLogOutputFileWrite returns an output writer that prints the transaction log to a JSON file.
+func JSONTransactionLogOutputFileWrite(options ...func(*fileSink)) TransactionLogOutputWriter {
+	sink := newFileSink("_transactions.json", os.O_WRONLY|os.O_CREATE, options...)
+	return func(transactionId string, startTimestamp time.Time, endTimestamp time.Time, transactionLoggerData *TransactionLoggerData) error {
+		return sink.write(func(f *os.File) error {
+			ret, err := f.Seek(-int64(len([]byte(endArray))), io.SeekEnd)
 			if err != nil {
-				return fmt.Errorf("error: could not write json file %v", err)
+				_, err := f.Write([]byte(startArray))
+				if err != nil {
+					return fmt.Errorf("error: could not write json file %v", err)
+				}
+				ret = int64(len([]byte(startArray)))
 			}
-			ret = int64(len([]byte(startArray)))
-		}
 
-		type OutputJSON struct {
-			TransactionID   string                 `json:"transactionId"`
-			StartTimestamp  time.Time              `json:"startTimestamp"`
-			EndTimestamp    time.Time              `json:"endTimestamp"`
-			TransactionLogs *TransactionLoggerData `json:"transactionData"`
-		}
-		outputJSON := &OutputJSON{
-			TransactionID:   transactionId,
-			StartTimestamp:  startTimestamp,
-			EndTimestamp:    endTimestamp,
-			TransactionLogs: transactionLoggerData,
-		}
+			loggerDataBytes, err := json.MarshalIndent(newTransactionOutputJSON(transactionId, startTimestamp, endTimestamp, transactionLoggerData), indent, indent)
+			if err != nil {
+				return fmt.Errorf("error: could not marshal logger data %v", err)
+			}
 
-		loggerDataBytes, err := json.MarshalIndent(outputJSON, indent, indent)
-		if err != nil {
-			return fmt.Errorf("error: could not marshal logger data %v", err)
-		}
+			writtenDataBytes := []byte(objectDelimiter)
+			if ret == int64(len([]byte(startArray))) {
+				writtenDataBytes = []byte(indent)
+			}
+			writtenDataBytes = append(writtenDataBytes, loggerDataBytes...)
 
-		writtenDataBytes := []byte(objectDelimiter)
-		if ret == int64(len([]byte(startArray))) {
-			writtenDataBytes = []byte(indent)
-		}
-		writtenDataBytes = append(writtenDataBytes, loggerDataBytes...)
+			_, err = f.WriteAt(writtenDataBytes, ret)
+			if err != nil {
+				return fmt.Errorf("error: could not write json file %v", err)
+			}
 
-		_, err = f.WriteAt(writtenDataBytes, ret)
-		if err != nil {
-			return fmt.Errorf("error: could not write json file %v", err)
-		}
+			_, err = f.WriteAt([]byte(endArray), ret+int64(len(writtenDataBytes)))
+			if err != nil {
+				return fmt.Errorf("error: could not write json file %v", err)
+			}
 
-		_, err = f.WriteAt([]byte(endArray), ret+int64(len(writtenDataBytes)))
+			return nil
+		})
+	}
+}
+
+// JSONLinesTransactionLogOutputFileWrite returns an output writer that appends the transaction log to a JSON Lines (NDJSON) file,
+// one JSON object per line.
+func JSONLinesTransactionLogOutputFileWrite(options ...func(*fileSink)) TransactionLogOutputWriter {
+	sink := newFileSink("_transactions.jsonl", os.O_WRONLY|os.O_CREATE|os.O_APPEND, options...)
+	return func(transactionId string, startTimestamp time.Time, endTimestamp time.Time, transactionLoggerData *TransactionLoggerData) error {
+		loggerDataBytes, err := json.Marshal(newTransactionOutputJSON(transactionId, startTimestamp, endTimestamp, transactionLoggerData))
 		if err != nil {
-			return fmt.Errorf("error: could not write json file %v", err)
+			return fmt.Errorf("error: could not marshal logger data %v", err)
 		}
 
-		return nil
+		return sink.append(append(loggerDataBytes, '\n'))
 	}
 }
 
 // TextTransactionLogOutputFileWrite returns an output writer that prints the transaction log to a text file.
-func TextTransactionLogOutputFileWrite() TransactionLogOutputWriter {
+func TextTransactionLogOutputFileWrite(options ...func(*fileSink)) TransactionLogOutputWriter {
+	sink := newFileSink("_transactions.json", os.O_WRONLY|os.O_CREATE|os.O_APPEND, options...)
 	return func(transactionId string, startTimestamp time.Time, endTimestamp time.Time, transactionLoggerData *TransactionLoggerData) error {
-		fileName := fmt.Sprintf(filepath.Join(config.LoggerConfig.Logger.OutputDir, "%s_transactions.json"), time.Now().Format(fileTimestampFormat))
-		f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
-		if err != nil {
-			return fmt.Errorf("error: could not open text file %v", err)
-		}
-		defer f.Close()
-
-		f.WriteString(fmt.Sprintf("[%s] Transaction {%s} started!\n", startTimestamp.Format(timestampFormat), transactionId))
-
-		for _, entry := range transactionLoggerData.TransactionLogs {
-			f.WriteString(fmt.Sprintf("--> [%s] [%s] %s", entry.Timestamp.Format(timestampFormat), entry.LoggerLevel, entry.Message))
-			for k, v := range entry.MetaData {
-				typeName := reflect.TypeOf(v).Name()
-				if strings.Contains(typeName, "int") {
-					f.WriteString(fmt.Sprintf(" [%s=%d]", k, v))
-				} else if strings.Contains(typeName, "float") {
-					f.WriteString(fmt.Sprintf(" [%s=%f]", k, v))
-				} else if typeName == "string" {
-					f.WriteString(fmt.Sprintf(" [%s=%s]", k, v))
-				} else {
-					f.WriteString("\n")
-					return fmt.Errorf("error: formatting for this variable type is not implement %s", typeName)
-				}
-			}
-			f.WriteString("\n")
-		}
-
-		f.WriteString(fmt.Sprintf("[%s] Transaction {%s} ended!\n", endTimestamp.Format(timestampFormat), transactionId))
-		return nil
+		return sink.append([]byte(formatTransactionLoggerData(transactionId, startTimestamp, endTimestamp, transactionLoggerData)))
 	}
 }
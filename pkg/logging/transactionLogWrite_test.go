@@ -44,8 +44,8 @@ var testTransactionLogging = TransactionLoggerData{
 func transactionLogFormatStart(transactionId string, startTimestamp time.Time) string {
 	return fmt.Sprintf("[%s] Transaction {%s} started!", startTimestamp.Format(timestampFormat), transactionId)
 }
-func transactionLogFormatEnd(transactionId string, endTimestamp time.Time) string {
-	return fmt.Sprintf("[%s] Transaction {%s} ended!", endTimestamp.Format(timestampFormat), transactionId)
+func transactionLogFormatEnd(transactionId string, startTimestamp time.Time, endTimestamp time.Time) string {
+	return fmt.Sprintf("[%s] Transaction {%s} ended! [duration=%s]", endTimestamp.Format(timestampFormat), transactionId, endTimestamp.Sub(startTimestamp))
 }
 
 func TestCLITransactionLogOutputWrite(t *testing.T) {
@@ -65,7 +65,7 @@ func TestCLITransactionLogOutputWrite(t *testing.T) {
 
 	assert.True(t, len(testTransactionLogging.TransactionLogs) == len(outputLines)-3)
 	assert.Equal(t, transactionLogFormatStart(testTransactionId, now), outputLines[0])
-	assert.Equal(t, transactionLogFormatEnd(testTransactionId, testEndTimestamp), outputLines[len(outputLines)-2])
+	assert.Equal(t, transactionLogFormatEnd(testTransactionId, now, testEndTimestamp), outputLines[len(outputLines)-2])
 
 	for i := 1; i <= len(outputLines)-3; i++ {
 		assert.Contains(t, outputLines[i], "--> "+logFormat(*testTransactionLogging.TransactionLogs[i-1]))
@@ -130,6 +130,10 @@ func TestTextTransactionLogOutputFileWrite(t *testing.T) {
 	if err != nil {
 		t.Fatalf("fatal: could not write logs to text file %v", err)
 	}
+	err = FlushFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not flush the file sinks %v", err)
+	}
 
 	f, err := os.OpenFile(filepath.Join(config.LoggerConfig.Logger.OutputDir, generatedFileName), os.O_RDONLY, 0644)
 	if err != nil {
@@ -148,7 +152,7 @@ func TestTextTransactionLogOutputFileWrite(t *testing.T) {
 
 	assert.True(t, len(testTransactionLogging.TransactionLogs) == len(outputLines)-3)
 	assert.Equal(t, transactionLogFormatStart(testTransactionId, now), outputLines[0])
-	assert.Equal(t, transactionLogFormatEnd(testTransactionId, testEndTimestamp), outputLines[len(outputLines)-2])
+	assert.Equal(t, transactionLogFormatEnd(testTransactionId, now, testEndTimestamp), outputLines[len(outputLines)-2])
 
 	for i := 1; i <= len(outputLines)-3; i++ {
 		assert.Contains(t, outputLines[i], "--> "+logFormat(*testTransactionLogging.TransactionLogs[i-1]))
@@ -157,3 +161,177 @@ func TestTextTransactionLogOutputFileWrite(t *testing.T) {
 		assert.Contains(t, outputLines[i], fmt.Sprintf("varFloat=%f", testTransactionLogging.TransactionLogs[i-1].MetaData["varFloat"]))
 	}
 }
+
+func TestLogfmtTransactionLogOutputWrite(t *testing.T) {
+	output, err := itesting.CaptureOutput(func() error {
+		err := LogfmtTransactionLogOutputWrite()(testTransactionId, now, testEndTimestamp, &testTransactionLogging)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("fatal: could not capture stdout output %v", err)
+	}
+
+	outputLines := strings.Split(output, "\n")
+
+	assert.Len(t, outputLines, len(testTransactionLogging.TransactionLogs)+3)
+	assert.Equal(t, fmt.Sprintf(`ts=%s level=error transactionId=%s msg="transaction started"`, now.Format(time.RFC3339Nano), testTransactionId), outputLines[0])
+	assert.Equal(t, fmt.Sprintf(`ts=%s level=info transactionId=%s msg=test1 varFloat=3.140000 varInt=1 varStr=string1`, now.Format(time.RFC3339Nano), testTransactionId), outputLines[1])
+	assert.Equal(t, fmt.Sprintf(`ts=%s level=error transactionId=%s msg="transaction ended" duration=1s`, testEndTimestamp.Format(time.RFC3339Nano), testTransactionId), outputLines[3])
+}
+
+func TestJSONLinesTransactionLogOutputFileWrite(t *testing.T) {
+	setupTestEnvironment(t, transactionLogTestDirName)
+
+	generatedFileName := fmt.Sprintf("%s_transactions.jsonl", time.Now().Format(fileTimestampFormat))
+	t.Cleanup(func() {
+		cleanup(t, []string{generatedFileName})
+	})
+
+	err := JSONLinesTransactionLogOutputFileWrite()(testTransactionId, now, testEndTimestamp, &testTransactionLogging)
+	if err != nil {
+		t.Fatalf("fatal: could not write logs to json lines file %v", err)
+	}
+	err = FlushFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not flush the file sinks %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(config.LoggerConfig.Logger.OutputDir, generatedFileName))
+	if err != nil {
+		t.Fatalf("error: could not read from json lines file %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	assert.Len(t, lines, 1)
+
+	var log struct {
+		TransactionId         string    `json:"transactionId"`
+		StartTimestamp        time.Time `json:"startTimestamp"`
+		EndTimestamp          time.Time `json:"endTimestamp"`
+		TransactionLoggerData `json:"transactionData"`
+	}
+	if err = json.Unmarshal([]byte(lines[0]), &log); err != nil {
+		t.Fatalf("error: could not decode json line %v", err)
+	}
+
+	assert.Equal(t, testTransactionId, log.TransactionId)
+	assert.True(t, now.Equal(log.StartTimestamp))
+	assert.True(t, testEndTimestamp.Equal(log.EndTimestamp))
+	assert.Equal(t, testTransactionLogging.LoggerLevel, log.LoggerLevel)
+	assert.Len(t, log.TransactionLogs, len(testTransactionLogging.TransactionLogs))
+}
+
+func TestJSONLinesTransactionLogOutputFileWriteWithSubTransactions(t *testing.T) {
+	setupTestEnvironment(t, transactionLogTestDirName)
+
+	generatedFileName := fmt.Sprintf("%s_transactions.jsonl", time.Now().Format(fileTimestampFormat))
+	t.Cleanup(func() {
+		cleanup(t, []string{generatedFileName})
+	})
+
+	transactionLoggerData := testNestedTransactionLoggerData()
+	err := JSONLinesTransactionLogOutputFileWrite()(testTransactionId, now, testEndTimestamp, transactionLoggerData)
+	if err != nil {
+		t.Fatalf("fatal: could not write logs to json lines file %v", err)
+	}
+	err = FlushFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not flush the file sinks %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(config.LoggerConfig.Logger.OutputDir, generatedFileName))
+	if err != nil {
+		t.Fatalf("error: could not read from json lines file %v", err)
+	}
+
+	var log struct {
+		TransactionLoggerData `json:"transactionData"`
+	}
+	if err = json.Unmarshal(b, &log); err != nil {
+		t.Fatalf("error: could not decode json line %v", err)
+	}
+
+	assert.Len(t, log.SubTransactions, 1)
+	payment := log.SubTransactions[0]
+	assert.Equal(t, "payment", payment.TransactionID)
+	assert.True(t, transactionLoggerData.SubTransactions[0].StartTimestamp.Equal(payment.StartTimestamp))
+	assert.True(t, transactionLoggerData.SubTransactions[0].EndTimestamp.Equal(payment.EndTimestamp))
+	assert.Len(t, payment.SubTransactions, 1)
+	assert.Equal(t, "charge card", payment.SubTransactions[0].TransactionID)
+	assert.Equal(t, "charged", payment.SubTransactions[0].TransactionLogs[0].Message)
+}
+
+func TestJSONLinesTransactionLogOutputFileWriteWithStatus(t *testing.T) {
+	setupTestEnvironment(t, transactionLogTestDirName)
+
+	generatedFileName := fmt.Sprintf("%s_transactions.jsonl", time.Now().Format(fileTimestampFormat))
+	t.Cleanup(func() {
+		cleanup(t, []string{generatedFileName})
+	})
+
+	err := JSONLinesTransactionLogOutputFileWrite()(testTransactionId, now, now.Add(5*time.Second), testNestedTransactionLoggerData())
+	if err != nil {
+		t.Fatalf("fatal: could not write logs to json lines file %v", err)
+	}
+	err = FlushFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not flush the file sinks %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(config.LoggerConfig.Logger.OutputDir, generatedFileName))
+	if err != nil {
+		t.Fatalf("error: could not read from json lines file %v", err)
+	}
+
+	var log map[string]any
+	if err = json.Unmarshal(b, &log); err != nil {
+		t.Fatalf("error: could not decode json line %v", err)
+	}
+
+	assert.Equal(t, float64(5000), log["durationMs"])
+	assert.Equal(t, "error", log["status"])
+	assert.Equal(t, "card declined", log["statusMessage"])
+
+	transactionData := log["transactionData"].(map[string]any)
+	assert.NotContains(t, transactionData, "status")
+	payment := transactionData["subTransactions"].([]any)[0].(map[string]any)
+	assert.Equal(t, "payment", payment["transactionId"])
+	assert.Equal(t, float64(2000), payment["durationMs"])
+	assert.NotContains(t, payment, "status")
+	charge := payment["subTransactions"].([]any)[0].(map[string]any)
+	assert.Equal(t, float64(1000), charge["durationMs"])
+	assert.Equal(t, "error", charge["status"])
+	assert.Equal(t, "card declined", charge["statusMessage"])
+}
+
+func TestJSONLinesTransactionLogOutputFileWriteWithDroppedLogs(t *testing.T) {
+	setupTestEnvironment(t, transactionLogTestDirName)
+
+	generatedFileName := fmt.Sprintf("%s_transactions.jsonl", time.Now().Format(fileTimestampFormat))
+	t.Cleanup(func() {
+		cleanup(t, []string{generatedFileName})
+	})
+
+	err := JSONLinesTransactionLogOutputFileWrite()(testTransactionId, now, now.Add(time.Second), &TransactionLoggerData{LoggerLevel: LevelInfo, DroppedLogs: 3, Partial: true})
+	if err != nil {
+		t.Fatalf("fatal: could not write logs to json lines file %v", err)
+	}
+	err = FlushFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not flush the file sinks %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(config.LoggerConfig.Logger.OutputDir, generatedFileName))
+	if err != nil {
+		t.Fatalf("error: could not read from json lines file %v", err)
+	}
+
+	var log map[string]any
+	if err = json.Unmarshal(b, &log); err != nil {
+		t.Fatalf("error: could not decode json line %v", err)
+	}
+
+	assert.Equal(t, float64(3), log["droppedLogs"])
+	assert.Equal(t, true, log["partial"])
+	assert.NotContains(t, log["transactionData"], "droppedLogs")
+}
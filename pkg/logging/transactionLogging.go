@@ -8,13 +8,27 @@ import (
 )
 
 const (
-	waitDurationUntilTransactionStop = 2 * time.Second
+	defaultTransactionStopTimeout = 2 * time.Second
 )
 
-// A TransactionLoggerData holds the transaction logs for a transaction
+// A TransactionLoggerData holds the transaction logs and the sub-transactions for a transaction
 type TransactionLoggerData struct {
-	LoggerLevel     loggerLevel   `json:"loggerLevel"`
-	TransactionLogs []*LoggerData `json:"transactionLogs"`
+	LoggerLevel     Level                       `json:"loggerLevel"`
+	TransactionLogs []*LoggerData               `json:"transactionLogs"`
+	SubTransactions []*SubTransactionLoggerData `json:"subTransactions,omitempty"`
+	Status          TransactionStatus           `json:"-"` // written next to the timestamps by the JSON writers
+	StatusMessage   string                      `json:"-"`
+	DroppedLogs     int                         `json:"-"` // the logs discarded by the buffer limit
+	Partial         bool                        `json:"-"` // a chunk written before the transaction ended, see OverflowFlush
+}
+
+// A SubTransactionLoggerData holds a step of a transaction, with its own timestamps, logs and sub-transactions.
+// The sub-transactions are written together with their root transaction.
+type SubTransactionLoggerData struct {
+	TransactionID  string    `json:"transactionId"`
+	StartTimestamp time.Time `json:"startTimestamp"`
+	EndTimestamp   time.Time `json:"endTimestamp"`
+	TransactionLoggerData
 }
 
 // A transactionLogging holds the top-level configuration of the transaction logger.
@@ -22,63 +36,96 @@ type TransactionLoggerData struct {
 // The startTimestamp is set when the Transaction is started.
 type transactionLogging struct {
 	transactionId  string
-	loggerLevel    loggerLevel
+	loggerLevel    Level
 	startTimestamp time.Time
 	outputWrite    TransactionLogOutputWriter
+	fields         MetaData
+	origin         *transactionLogging // the logger this one was derived from, using With
+	stopTimeout    time.Duration
+	maxLifetime    time.Duration
+	flushLevel     Level // the level a log must reach for the transaction to be written, every transaction is written if unset
+	bufferLimit    transactionBufferLimit
+	expiryTimer    *time.Timer               // stops the transaction once it exceeds its maximum lifetime
+	repanic        bool                      // re-panic after a panic was recovered and written by RunInTransaction
+	state          *transactionState         // the started transaction, set on the root logger and on the sub-transaction loggers
+	subTransaction *SubTransactionLoggerData // the sub-transaction the logs are added to, nil for the root transaction
+	caller         bool                      // record the code adding every log, see WithTransactionCaller
+	callerSkip     int
+	errorStack     bool // record the stack in the Err attributes of the Error logs, see WithTransactionErrorStack
+}
+
+// A transactionState holds the data of a started transaction. Every transaction owns its lock,
+// so that the transactions running in parallel do not wait for each other.
+type transactionState struct {
+	transactionId  string
+	startTimestamp time.Time
+	inFlight       *inFlightLogs
+
+	flushLevel Level
+
+	mutex     sync.Mutex // guards the fields below, and the sub-transactions of the data
+	data      *TransactionLoggerData
+	stopped   bool
+	triggered bool // a log reached the flush level
+	buffer    transactionBuffer
+}
+
+// A inFlightLogs tracks the logs that are being added to a transaction, so that stopping the transaction can wait for them.
+type inFlightLogs struct {
+	mutex    sync.Mutex
+	pending  int
+	stopping bool
+	drained  chan struct{}
 }
 
 // A transactionMap is an active transaction holder for the transaction logger.
+// It is only used to keep the transaction ids unique and to list the open transactions,
+// the logs are added to the transactionState held by the loggers.
 type transactionMap struct {
 	sync.Map
 }
 
 var transactionLoggerOnce sync.Once
-var transactionLoggerInstance *transactionLogging
 
-var availableTransactions *transactionMap // using hash map for increased read/write performance
+// transactionConfigOutputWrite is the output writer of the YAML configuration, created once and shared by every transaction
+var transactionConfigOutputWrite TransactionLogOutputWriter
 
-var addLogMutex sync.Mutex
-var writeTransactionLogOutputMutex sync.Mutex
+var availableTransactions *transactionMap // using hash map for increased read/write performance
 
 // NewLog creates a transaction logging instance, respective to the defined YAML configuration or by given "drivers" in form of options argument.
 // The "drivers" have a higher priority than YAML configuration.
 //
-// The transaction log configuration is set every time a transaction logging instance is defined.
+// The transaction log configuration is set every time a transaction logging instance is defined,
+// the output writer of the YAML configuration being created once and shared by every transaction.
 //
 // If a transaction with the same name is already present, the returned instance is nil.
 //
+// The configuration is read from the transactionLogger section of the YAML file, the unset output keys falling back to the logger section.
+//
 // Default:
 // If no configuration nor drivers are specified, the log level is Info with output to CLI.
 func NewTransactionLog(transactionId string, options ...func(*transactionLogging)) (*transactionLogging, error) {
 	transactionLoggerOnce.Do(func() {
 		config.Init()
+		config.PrintWarnings()
 
 		availableTransactions = &transactionMap{}
+		transactionConfigOutputWrite = transactionLogOutputWriterFromConfig(config.LoggerConfig.TransactionLoggerConfig())
 	})
 
-	transactionLoggerInstance = &transactionLogging{}
-
-	switch config.LoggerConfig.Logger.Level {
-	case string(LevelOff), string(LevelInfo), string(LevelWarning), string(LevelError), string(LevelDebug):
-		transactionLoggerInstance.loggerLevel = loggerLevel(config.LoggerConfig.Logger.Level)
-	default:
-		transactionLoggerInstance.loggerLevel = LevelInfo
-	}
-
-	switch config.LoggerConfig.Logger.OutputWriter {
-	case string(cli):
-		transactionLoggerInstance.outputWrite = CLITransactionLogOutputWrite()
-	case string(jsonFile):
-		transactionLoggerInstance.outputWrite = JSONTransactionLogOutputFileWrite()
-	case string(textFile):
-		transactionLoggerInstance.outputWrite = TextTransactionLogOutputFileWrite()
-	default:
-		transactionLoggerInstance.outputWrite = CLITransactionLogOutputWrite()
+	transactionConfig := config.LoggerConfig.TransactionLoggerConfig()
+	transactionLogger := &transactionLogging{
+		loggerLevel: loggerLevelFromConfig(transactionConfig.Level, LevelInfo),
+		outputWrite: transactionConfigOutputWrite,
+		stopTimeout: transactionDurationFromConfig(transactionConfig.StopTimeout, defaultTransactionStopTimeout),
+		maxLifetime: transactionDurationFromConfig(transactionConfig.MaxLifetime, 0),
+		flushLevel:  loggerLevelFromConfig(transactionConfig.FlushLevel, LevelOff),
+		bufferLimit: newTransactionBufferLimit(transactionConfig.MaxEntries, transactionConfig.MaxBytes, OverflowPolicy(transactionConfig.OverflowPolicy)),
 	}
 
 	// Log options override the YAML file configuration
 	for _, o := range options {
-		o(transactionLoggerInstance)
+		o(transactionLogger)
 	}
 
 	if _, ok := availableTransactions.Load(transactionId); ok {
@@ -86,18 +133,69 @@ func NewTransactionLog(transactionId string, options ...func(*transactionLogging
 		return nil, fmt.Errorf("error: the provided transaction was already started %s", transactionId)
 	}
 
-	transactionLoggerInstance.transactionId = transactionId
+	transactionLogger.transactionId = transactionId
+
+	return transactionLogger, nil
+}
+
+// transactionLogOutputWriterFromConfig creates the transaction output writer named by the YAML configuration, falling back to the CLI output writer.
+// The file output writers use the output directory, the file suffix and the rotation policy of the transaction configuration.
+func transactionLogOutputWriterFromConfig(transactionConfig config.TransactionLogger) TransactionLogOutputWriter {
+	fileOptions := []func(*fileSink){
+		WithFileOutputDir(transactionConfig.OutputDir),
+		WithFileRotation(fileRotationFromConfig(config.Logger{
+			MaxSizeMB:        *transactionConfig.MaxSizeMB,
+			RotationInterval: transactionConfig.RotationInterval,
+			Compress:         *transactionConfig.Compress,
+			MaxBackups:       *transactionConfig.MaxBackups,
+			MaxAgeDays:       *transactionConfig.MaxAgeDays,
+		})),
+	}
+	if transactionConfig.FileSuffix != "" {
+		fileOptions = append(fileOptions, WithFileSuffix(transactionConfig.FileSuffix))
+	}
 
-	return transactionLoggerInstance, nil
+	switch transactionConfig.OutputWriter {
+	case string(cli):
+		return CLITransactionLogOutputWrite()
+	case string(jsonFile):
+		return JSONTransactionLogOutputFileWrite(fileOptions...)
+	case string(textFile):
+		return TextTransactionLogOutputFileWrite(fileOptions...)
+	case string(logfmt):
+		return LogfmtTransactionLogOutputWrite()
+	case string(jsonLines):
+		return JSONLinesTransactionLogOutputFileWrite(fileOptions...)
+	default:
+		return CLITransactionLogOutputWrite()
+	}
 }
 
 // WithTransactionLoggerLevel is a pre-defined "driver" that specifies the transaction log level used
-func WithTransactionLoggerLevel(loggerLevel loggerLevel) func(*transactionLogging) {
+func WithTransactionLoggerLevel(loggerLevel Level) func(*transactionLogging) {
 	return func(l *transactionLogging) {
 		l.loggerLevel = loggerLevel
 	}
 }
 
+// WithTransactionStopTimeout is a pre-defined "driver" that specifies how long stopping a transaction waits
+// for the logs that are still being added to it. A timeout of 0 or lower does not wait.
+func WithTransactionStopTimeout(timeout time.Duration) func(*transactionLogging) {
+	return func(l *transactionLogging) {
+		l.stopTimeout = timeout
+	}
+}
+
+// transactionDurationFromConfig converts a YAML transaction duration (e.g. the stop timeout), falling back to the default duration if it is unset or invalid.
+// The invalid durations of the YAML file are reported once, when the configuration is loaded.
+func transactionDurationFromConfig(value string, defaultDuration time.Duration) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultDuration
+	}
+	return duration
+}
+
 // WithTransactionLogOutputWriter is a pre-defined "driver" that specifies the transaction output writer used
 func WithTransactionLogOutputWriter(outputWriter TransactionLogOutputWriter) func(*transactionLogging) {
 	return func(l *transactionLogging) {
@@ -105,135 +203,433 @@ func WithTransactionLogOutputWriter(outputWriter TransactionLogOutputWriter) fun
 	}
 }
 
+// With returns a derived transaction logger that adds the given attributes to every log it registers in the transaction.
+// The attributes given to a log method override the bound attributes with the same key.
+//
+// The derived logger shares the transaction of its parent and can be derived further.
+// Starting or stopping the transaction from a derived logger has the same effect as doing it from the parent.
+func (l *transactionLogging) With(v MetaData) *transactionLogging {
+	derived := *l
+	derived.fields = mergeMetaData(l.fields, withErrorValues(v))
+	if l.origin == nil {
+		derived.origin = l
+	}
+	return &derived
+}
+
 // Info registers a log of level Info, for a specific transaction, with a message and additional attributes.
 //
 // If no attributes, use nil as MetaData
 func (l *transactionLogging) Info(msg string, v MetaData) {
-	l.processLoggerData(LevelInfo, msg, v)
+	l.processLoggerData(LevelInfo, msg, v, nil)
 }
 
 // Warning registers a log of level Warning, for a specific transaction, with a message and additional attributes.
 //
 // If no attributes, use nil as MetaData
 func (l *transactionLogging) Warning(msg string, v MetaData) {
-	l.processLoggerData(LevelWarning, msg, v)
+	l.processLoggerData(LevelWarning, msg, v, nil)
 }
 
 // Error registers a log of level Error, for a specific transaction, with a message and additional attributes.
 //
 // If no attributes, use nil as MetaData
 func (l *transactionLogging) Error(msg string, v MetaData) {
-	l.processLoggerData(LevelError, msg, v)
+	l.processLoggerData(LevelError, msg, v, nil)
 }
 
 // Debug registers a log of level Debug, for a specific transaction, with a message and additional attributes.
 //
 // If no attributes, use nil as MetaData
 func (l *transactionLogging) Debug(msg string, v MetaData) {
-	l.processLoggerData(LevelDebug, msg, v)
+	l.processLoggerData(LevelDebug, msg, v, nil)
+}
+
+// Trace registers a log of level Trace, for a specific transaction, with a message and additional attributes.
+//
+// If no attributes, use nil as MetaData
+func (l *transactionLogging) Trace(msg string, v MetaData) {
+	l.processLoggerData(LevelTrace, msg, v, nil)
+}
+
+// InfoFields registers a log of level Info, for a specific transaction, with a message and additional attributes kept in the given order.
+func (l *transactionLogging) InfoFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelInfo, msg, metaData, metaDataOrder)
+}
+
+// WarningFields registers a log of level Warning, for a specific transaction, with a message and additional attributes kept in the given order.
+func (l *transactionLogging) WarningFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelWarning, msg, metaData, metaDataOrder)
+}
+
+// ErrorFields registers a log of level Error, for a specific transaction, with a message and additional attributes kept in the given order.
+func (l *transactionLogging) ErrorFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelError, msg, metaData, metaDataOrder)
+}
+
+// DebugFields registers a log of level Debug, for a specific transaction, with a message and additional attributes kept in the given order.
+func (l *transactionLogging) DebugFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelDebug, msg, metaData, metaDataOrder)
+}
+
+// TraceFields registers a log of level Trace, for a specific transaction, with a message and additional attributes kept in the given order.
+func (l *transactionLogging) TraceFields(msg string, fields ...Field) {
+	metaData, metaDataOrder := fieldsToMetaData(fields)
+	l.processLoggerData(LevelTrace, msg, metaData, metaDataOrder)
 }
 
 // processLoggerData processes a transaction log triage.
-// Logs are added to transaction if the set transaction log level is higher or equal than the log method used (Info, Warning, Error, Debug).
+// Logs are added to transaction if the log method used (Info, Warning, Error, Debug...) is at least as severe as the set transaction log level.
+// With a flush level, the logs of every level are added to the transaction, see WithTransactionFlushLevel.
 //
 // If LogLevel is Off, no logs are kept.
-func (l *transactionLogging) processLoggerData(loggerLevel loggerLevel, msg string, metaData MetaData) {
-	if convertLoggerLevelToInt(loggerLevel) <= convertLoggerLevelToInt(l.loggerLevel) {
-		err := l.addLogToTransaction(&LoggerData{LoggerLevel: loggerLevel, Timestamp: time.Now(), Message: msg, MetaData: metaData})
+func (l *transactionLogging) processLoggerData(loggerLevel Level, msg string, metaData MetaData, metaDataOrder []string) {
+	if l.loggerLevel == LevelOff {
+		return
+	}
+	if l.flushLevelEnabled() || loggerLevelReached(loggerLevel, l.loggerLevel) {
+		metaData = withErrorValues(metaData)
+		if l.errorStack && loggerLevelReached(loggerLevel, LevelError) && hasErrorValue(metaData) {
+			metaData = withErrorStack(metaData, captureStack(l.callerSkip+1)) // the log method (Info, InfoFields...) calling processLoggerData
+		}
+		if len(l.fields) > 0 {
+			metaData = mergeMetaData(l.fields, metaData)
+		}
+		log := &LoggerData{LoggerLevel: loggerLevel, Timestamp: time.Now(), Message: msg, MetaData: metaData, MetaDataOrder: metaDataOrder}
+		if l.caller {
+			log.Caller = captureCaller(l.callerSkip + 1) // the log method (Info, InfoFields...) calling processLoggerData
+		}
+		err := l.addLogToTransaction(log)
 		if err != nil {
 			fmt.Println(err)
 		}
 	}
 }
 
-// addLogToTransaction adds log to a specific transaction
+// addLogToTransaction adds log to a specific transaction, respecting its buffer limit.
+// A partial chunk of the transaction is written if the buffer limit was reached, see WithTransactionBufferLimit.
 //
 // addLogToTransaction is safe to call concurrently with other operations and will
-// block until all other operations finish.
+// block until the other operations on the same transaction finish.
 func (l *transactionLogging) addLogToTransaction(log *LoggerData) error {
-	foundTransaction, ok := availableTransactions.Load(l.transactionId)
-	if !ok {
+	state := l.currentState()
+	if state == nil {
 		return fmt.Errorf("error: the provided transaction was not started or was recently ended %s", l.transactionId)
 	}
-
-	foundTransactionTyped, ok := foundTransaction.(*TransactionLoggerData)
-	if !ok {
-		return fmt.Errorf("error: the stored transaction is of unknown type %s", l.transactionId)
-	}
-
-	addLogMutex.Lock()
-	newTransaction := &TransactionLoggerData{
-		LoggerLevel:     foundTransactionTyped.LoggerLevel,
-		TransactionLogs: append(foundTransactionTyped.TransactionLogs, log),
+	var chunk *TransactionLoggerData
+	err := state.update(l.subTransaction, func(data *TransactionLoggerData) {
+		chunk = state.bufferLog(data, log)
+		if state.flushLevel != "" && loggerLevelReached(log.LoggerLevel, state.flushLevel) {
+			state.triggered = true
+		}
+	})
+	if err != nil || chunk == nil {
+		return err
 	}
-	availableTransactions.CompareAndSwap(l.transactionId, foundTransactionTyped, newTransaction)
-	addLogMutex.Unlock()
-	return nil
+	return l.outputWrite(l.transactionId, state.startTimestamp, time.Now(), chunk)
 }
 
-// StartTransactionLogging initiates the transaction logging process. It loads the specific transaction into a synchronised Hash Map.
+// StartTransactionLogging initiates the transaction logging process. It registers the specific transaction into a synchronised Hash Map,
+// and gives it its own buffer for the logs.
 //
 // If transaction exists or started already, an error will be returned.
 //
-// StartTransactionLogging is safe to call concurrently with other operations and will
-// block until all other operations finish.
+// StartTransactionLogging is safe to call concurrently with other operations.
 //
 // ! Call StopTransactionLogging when logging is finished.
 func (l *transactionLogging) StartTransactionLogging() error {
+	if l.subTransaction != nil {
+		return fmt.Errorf("error: the provided sub-transaction is started using StartSubTransaction %s", l.subTransaction.TransactionID)
+	}
+	if l.origin != nil {
+		return l.origin.StartTransactionLogging()
+	}
 	if l.loggerLevel == LevelOff {
 		return nil
 	}
 
-	if _, loaded := availableTransactions.LoadOrStore(l.transactionId, &TransactionLoggerData{
-		LoggerLevel:     l.loggerLevel,
-		TransactionLogs: []*LoggerData{},
-	}); loaded {
+	state := newTransactionState(l.transactionId, l.loggerLevel, time.Now())
+	if l.flushLevelEnabled() {
+		state.flushLevel = l.flushLevel
+	}
+	state.buffer.transactionBufferLimit = l.bufferLimit
+	if _, loaded := availableTransactions.LoadOrStore(l.transactionId, state); loaded {
 		return fmt.Errorf("error: the provided transaction was already started %s", l.transactionId)
 	}
 
-	l.startTimestamp = time.Now()
+	l.state = state
+	l.startTimestamp = state.startTimestamp
+	if l.maxLifetime > 0 {
+		l.expiryTimer = time.AfterFunc(l.maxLifetime, func() {
+			l.expire(state)
+		})
+	}
 
 	return nil
 }
 
 // StopTransactionLogging stops the transaction logging process. It deletes the specific transaction from the synchronised Hash Map.
-// After deletion, the transaction is written to the output using the specified Transaction OutputWriter.
+// Before deletion, it waits for the logs that are still being added to the transaction, at most for the stop timeout.
+// Logs registered after StopTransactionLogging was called are not added to the transaction.
+// After deletion, the transaction is written to the output using the specified Transaction OutputWriter,
+// unless none of its logs reached the flush level, see WithTransactionFlushLevel.
 // Will block until the writing is finished.
 //
 // StopTransactionLogging is safe to call concurrently with other operations and will
-// block until all other operations finish.
+// block until the other operations on the same transaction finish.
+//
+// The sub-transactions that were not stopped end with the transaction.
 //
 // If transaction does not exists or started already, an error will be returned.
+//
+// Called on a sub-transaction logger, StopTransactionLogging only ends the sub-transaction, see StartSubTransaction.
 func (l *transactionLogging) StopTransactionLogging() error {
+	if l.subTransaction != nil {
+		return l.stopSubTransaction()
+	}
+	if l.origin != nil {
+		return l.origin.StopTransactionLogging()
+	}
 	if l.loggerLevel == LevelOff {
 		return nil
 	}
-	endTimestamp := time.Now()
-
-	fmt.Printf("info: Will end logging transaction in a couple of seconds %s\n", l.transactionId)
-	time.Sleep(waitDurationUntilTransactionStop)
-
-	foundTransaction, loaded := availableTransactions.LoadAndDelete(l.transactionId)
-	if !loaded {
+	if l.state == nil {
 		return fmt.Errorf("error: the provided transaction was not started or was recently ended %s", l.transactionId)
 	}
-
-	foundTransactionTyped, ok := foundTransaction.(*TransactionLoggerData)
-	if !ok {
-		return fmt.Errorf("error: the stored transaction is of unknown type %s", l.transactionId)
+	if l.expiryTimer != nil {
+		l.expiryTimer.Stop()
 	}
+	return l.stopTransaction(l.state)
+}
 
-	// found transaction should not contain logs that do not sattisfy the log level set prior
+// stopTransaction stops the given transaction, once the logs that are being added to it are done, and writes it to the output.
+func (l *transactionLogging) stopTransaction(state *transactionState) error {
+	endTimestamp := time.Now()
 
-	writeTransactionLogOutputMutex.Lock()
+	transactionLoggerData, err := state.stop(l.stopTimeout, endTimestamp)
+	if err != nil {
+		return err
+	}
+	if state.discarded() {
+		return nil
+	}
 
-	err := l.outputWrite(l.transactionId, l.startTimestamp, endTimestamp, foundTransactionTyped)
+	// the transaction does not contain logs that do not sattisfy the log level set prior
+	err = l.outputWrite(l.transactionId, state.startTimestamp, endTimestamp, transactionLoggerData)
 	if err != nil {
 		fmt.Println(err)
-		writeTransactionLogOutputMutex.Unlock()
 		return err
 	}
+	return nil
+}
+
+// StartSubTransaction starts a step of the transaction (e.g. "reserve stock" in a "checkout" transaction),
+// and returns a logger that adds its logs to the sub-transaction. The sub-transaction records its own start and end timestamps,
+// and can be divided further by starting sub-transactions from the returned logger.
+//
+// Call StopTransactionLogging on the returned logger to end the sub-transaction.
+// The sub-transactions are written together with the root transaction, when it is stopped.
+//
+// If the transaction was not started or was already stopped, an error will be returned.
+//
+// StartSubTransaction is safe to call concurrently with other operations and will
+// block until the other operations on the same transaction finish.
+func (l *transactionLogging) StartSubTransaction(subTransactionId string) (*transactionLogging, error) {
+	if l.loggerLevel == LevelOff {
+		return &transactionLogging{loggerLevel: LevelOff}, nil
+	}
+
+	state := l.currentState()
+	if state == nil {
+		return nil, fmt.Errorf("error: the provided transaction was not started or was recently ended %s", l.transactionId)
+	}
+
+	subTransaction := &SubTransactionLoggerData{
+		TransactionID:  subTransactionId,
+		StartTimestamp: time.Now(),
+		TransactionLoggerData: TransactionLoggerData{
+			LoggerLevel:     l.loggerLevel,
+			TransactionLogs: []*LoggerData{},
+		},
+	}
+	err := state.update(l.subTransaction, func(data *TransactionLoggerData) {
+		data.SubTransactions = append(data.SubTransactions, subTransaction)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subLogger := *l
+	subLogger.origin = l.root()
+	subLogger.state = state
+	subLogger.subTransaction = subTransaction
+	return &subLogger, nil
+}
+
+// stopSubTransaction ends the sub-transaction of the logger.
+//
+// If the sub-transaction or its transaction was already stopped, an error will be returned.
+func (l *transactionLogging) stopSubTransaction() error {
+	if l.loggerLevel == LevelOff {
+		return nil
+	}
+	endTimestamp := time.Now()
+
+	return l.state.update(l.subTransaction, func(*TransactionLoggerData) {
+		l.subTransaction.EndTimestamp = endTimestamp
+		if l.subTransaction.Status == "" {
+			l.subTransaction.Status = TransactionStatusOk
+		}
+	})
+}
 
-	writeTransactionLogOutputMutex.Unlock()
+// endSubTransactions ends the sub-transactions that were not stopped, and their own sub-transactions, at the given timestamp.
+// The sub-transactions without a status take the status of the transaction they end with.
+func endSubTransactions(subTransactions []*SubTransactionLoggerData, endTimestamp time.Time, status TransactionStatus) {
+	for _, subTransaction := range subTransactions {
+		if subTransaction.EndTimestamp.IsZero() {
+			subTransaction.EndTimestamp = endTimestamp
+			if subTransaction.Status == "" {
+				subTransaction.Status = status
+			}
+		}
+		endSubTransactions(subTransaction.SubTransactions, endTimestamp, subTransaction.Status)
+	}
+}
+
+// root returns the logger the transaction was started from, shared by every logger derived from it using With.
+func (l *transactionLogging) root() *transactionLogging {
+	if l.origin != nil {
+		return l.origin
+	}
+	return l
+}
+
+// currentState returns the started transaction the logger adds its logs to, nil if the transaction was not started.
+// A sub-transaction logger keeps the transaction it was started in.
+func (l *transactionLogging) currentState() *transactionState {
+	if l.subTransaction != nil {
+		return l.state
+	}
+	return l.root().state
+}
+
+// newTransactionState creates the state of a transaction started at the given timestamp, with an empty buffer.
+func newTransactionState(transactionId string, loggerLevel Level, startTimestamp time.Time) *transactionState {
+	return &transactionState{
+		transactionId:  transactionId,
+		startTimestamp: startTimestamp,
+		inFlight:       newInFlightLogs(),
+		data: &TransactionLoggerData{
+			LoggerLevel:     loggerLevel,
+			TransactionLogs: []*LoggerData{},
+		},
+	}
+}
+
+// update calls fn with the data of the transaction, or of the given sub-transaction, while holding the lock of the transaction.
+//
+// If the transaction or the sub-transaction was already stopped, an error will be returned and fn is not called.
+func (s *transactionState) update(subTransaction *SubTransactionLoggerData, fn func(data *TransactionLoggerData)) error {
+	if !s.inFlight.add() {
+		return fmt.Errorf("error: the provided transaction was not started or was recently ended %s", s.transactionId)
+	}
+	defer s.inFlight.done()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stopped {
+		return fmt.Errorf("error: the provided transaction was not started or was recently ended %s", s.transactionId)
+	}
+	if subTransaction != nil {
+		if !subTransaction.EndTimestamp.IsZero() {
+			return fmt.Errorf("error: the provided sub-transaction was recently ended %s", subTransaction.TransactionID)
+		}
+		fn(&subTransaction.TransactionLoggerData)
+		return nil
+	}
+	fn(s.data)
 	return nil
 }
+
+// stop waits for the logs that are being added to the transaction, at most for the given timeout,
+// then ends the transaction and its open sub-transactions and deletes it from the synchronised Hash Map.
+// It returns the data of the transaction, that is no longer modified.
+//
+// If the transaction was already stopped, an error will be returned.
+func (s *transactionState) stop(timeout time.Duration, endTimestamp time.Time) (*TransactionLoggerData, error) {
+	s.inFlight.drain(timeout)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stopped {
+		return nil, fmt.Errorf("error: the provided transaction was not started or was recently ended %s", s.transactionId)
+	}
+	s.stopped = true
+	availableTransactions.CompareAndDelete(s.transactionId, s)
+
+	if s.data.Status == "" {
+		s.data.Status = TransactionStatusOk
+	}
+	endSubTransactions(s.data.SubTransactions, endTimestamp, s.data.Status)
+	return s.data, nil
+}
+
+// newInFlightLogs creates a tracker that accepts logs until it is drained.
+func newInFlightLogs() *inFlightLogs {
+	return &inFlightLogs{
+		drained: make(chan struct{}),
+	}
+}
+
+// add registers a log that is being added to the transaction.
+// It returns false if the transaction is stopping and the log must not be added.
+func (i *inFlightLogs) add() bool {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.stopping {
+		return false
+	}
+	i.pending++
+	return true
+}
+
+// done unregisters a log that was added to the transaction.
+func (i *inFlightLogs) done() {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.pending--
+	if i.stopping && i.pending == 0 {
+		close(i.drained)
+	}
+}
+
+// drain stops accepting logs and waits until the logs that are being added are done, at most for the given timeout.
+// It returns false if the timeout elapsed first.
+func (i *inFlightLogs) drain(timeout time.Duration) bool {
+	i.mutex.Lock()
+	i.stopping = true
+	pending := i.pending
+	i.mutex.Unlock()
+	if pending == 0 {
+		return true
+	}
+	if timeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-i.drained:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -1,10 +1,14 @@
 package logging
 
 import (
+	"context"
 	"fmt"
 	"go-telemetry/pkg/internal/config"
 	itesting "go-telemetry/pkg/internal/telemetrytesting"
+	"os"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,9 +17,11 @@ import (
 
 const (
 	// output writers fn names
-	CLITransactionLogOutputWriteName      = "CLITransactionLogOutputWrite"
-	JSONTransactionLogOutputFileWriteName = "JSONTransactionLogOutputFileWrite"
-	TextTransactionLogOutputFileWriteName = "TextTransactionLogOutputFileWrite"
+	CLITransactionLogOutputWriteName           = "CLITransactionLogOutputWrite"
+	JSONTransactionLogOutputFileWriteName      = "JSONTransactionLogOutputFileWrite"
+	TextTransactionLogOutputFileWriteName      = "TextTransactionLogOutputFileWrite"
+	LogfmtTransactionLogOutputWriteName        = "LogfmtTransactionLogOutputWrite"
+	JSONLinesTransactionLogOutputFileWriteName = "JSONLinesTransactionLogOutputFileWrite"
 )
 
 const transactionLogTestDirName = "testtransactionlog"
@@ -25,7 +31,7 @@ var testTransactionId = "testTransaction"
 
 func TestNewTransactionLogWithYAMLConfigTableDriven(t *testing.T) {
 	type Expected struct {
-		Level            loggerLevel
+		Level            Level
 		OutputWriterName string
 	}
 	type TestCase struct {
@@ -90,6 +96,28 @@ func TestNewTransactionLogWithYAMLConfigTableDriven(t *testing.T) {
 				OutputWriterName: TextTransactionLogOutputFileWriteName,
 			},
 		},
+		{
+			TestName: "Log level warning, output writer logfmt",
+			Data: config.Logger{
+				Level:        string(LevelWarning),
+				OutputWriter: string(logfmt),
+			},
+			Expected: Expected{
+				Level:            LevelWarning,
+				OutputWriterName: LogfmtTransactionLogOutputWriteName,
+			},
+		},
+		{
+			TestName: "Log level debug, output writer json lines file",
+			Data: config.Logger{
+				Level:        string(LevelDebug),
+				OutputWriter: string(jsonLines),
+			},
+			Expected: Expected{
+				Level:            LevelDebug,
+				OutputWriterName: JSONLinesTransactionLogOutputFileWriteName,
+			},
+		},
 		{
 			TestName: "Log level error, output writer invalid value",
 			Data: config.Logger{
@@ -136,6 +164,89 @@ func TestNewTransactionLogWithYAMLConfigTableDriven(t *testing.T) {
 	}
 }
 
+func TestNewTransactionLogWithTransactionLoggerConfig(t *testing.T) {
+	setupTestEnvironment(t, transactionLogTestDirName)
+	transactionLoggerOnce = sync.Once{}
+	config.LoggerConfig.Logger.Level = string(LevelWarning)
+	config.LoggerConfig.Logger.OutputWriter = string(cli)
+	config.LoggerConfig.TransactionLogger = config.TransactionLogger{
+		Level:        string(LevelDebug),
+		OutputWriter: string(jsonLines),
+		FileSuffix:   "_orders.jsonl",
+		StopTimeout:  "1s",
+		MaxEntries:   10,
+	}
+	generatedFileName := fmt.Sprintf("%s_orders.jsonl", time.Now().Format(fileTimestampFormat))
+	t.Cleanup(func() {
+		cleanup(t, []string{generatedFileName})
+		config.LoggerConfig = &config.Config{}
+	})
+
+	log, err := NewTransactionLog(testTransactionId)
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+	assert.Equal(t, LevelDebug, log.loggerLevel)
+	assert.Equal(t, time.Second, log.stopTimeout)
+	assert.Equal(t, 10, log.bufferLimit.maxEntries)
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	log.Debug("test debug", nil)
+	err = log.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+	err = FlushFileSinks()
+	if err != nil {
+		t.Fatalf("fatal: could not flush the file sinks %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(config.LoggerConfig.Logger.OutputDir, generatedFileName))
+	if err != nil {
+		t.Fatalf("error: could not read from json lines file %v", err)
+	}
+	assert.Contains(t, string(b), "test debug")
+}
+
+func TestNewTransactionLogSharesFileSink(t *testing.T) {
+	setupTestEnvironment(t, transactionLogTestDirName)
+	transactionLoggerOnce = sync.Once{}
+	config.LoggerConfig.TransactionLogger.OutputWriter = string(jsonLines)
+	generatedFileName := fmt.Sprintf("%s_transactions.jsonl", time.Now().Format(fileTimestampFormat))
+	t.Cleanup(func() {
+		cleanup(t, []string{generatedFileName})
+		config.LoggerConfig = &config.Config{}
+		// the configured output writer is cached, do not leak the json lines writer to the next tests
+		transactionLoggerOnce = sync.Once{}
+		transactionConfigOutputWrite = nil
+	})
+
+	for i := range 200 {
+		log, err := NewTransactionLog(fmt.Sprintf("%s%d", testTransactionId, i))
+		if err != nil {
+			t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+		}
+		err = log.StartTransactionLogging()
+		if err != nil {
+			t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+		}
+		err = log.StopTransactionLogging()
+		if err != nil {
+			t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+		}
+	}
+
+	openSinks := 0
+	openFileSinks.Range(func(_, _ any) bool {
+		openSinks++
+		return true
+	})
+	assert.Equal(t, 1, openSinks)
+}
+
 func TestNewTransactionLogWhenTransactionExists(t *testing.T) {
 	log1, err := NewTransactionLog(testTransactionId)
 	if err != nil {
@@ -163,6 +274,44 @@ func TestWithTransactionLoggerLevel(t *testing.T) {
 	assert.Equal(t, LevelDebug, l.loggerLevel)
 }
 
+func TestWithTransactionStopTimeout(t *testing.T) {
+	l := transactionLogging{}
+	WithTransactionStopTimeout(time.Second)(&l)
+	assert.Equal(t, time.Second, l.stopTimeout)
+}
+
+func TestTransactionDurationFromConfigTableDriven(t *testing.T) {
+	type TestCase struct {
+		TestName string
+		Data     string
+		Expected time.Duration
+	}
+
+	testCases := []TestCase{
+		{
+			TestName: "Unset timeout",
+			Data:     "",
+			Expected: defaultTransactionStopTimeout,
+		},
+		{
+			TestName: "Invalid timeout",
+			Data:     "undefined",
+			Expected: defaultTransactionStopTimeout,
+		},
+		{
+			TestName: "Valid timeout",
+			Data:     "500ms",
+			Expected: 500 * time.Millisecond,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.TestName, func(t *testing.T) {
+			assert.Equal(t, test.Expected, transactionDurationFromConfig(test.Data, defaultTransactionStopTimeout))
+		})
+	}
+}
+
 func TestWithTransactionLogOutputWriter(t *testing.T) {
 	CLI := CLITransactionLogOutputWrite()
 	l := transactionLogging{}
@@ -244,8 +393,8 @@ func TestTransactionInfo(t *testing.T) {
 	assert.Contains(t, bytes, "varStr=string")
 	assert.Contains(t, bytes, "varFloat=3.14")
 	assert.Contains(t, bytes, "test info")
-	assert.NotContains(t, bytes, "test warning")
-	assert.NotContains(t, bytes, "test error")
+	assert.Contains(t, bytes, "test warning")
+	assert.Contains(t, bytes, "test error")
 	assert.NotContains(t, bytes, "test debug")
 }
 
@@ -285,9 +434,9 @@ func TestTransactionWarning(t *testing.T) {
 	assert.Contains(t, bytes, "varInt=0")
 	assert.Contains(t, bytes, "varStr=string")
 	assert.Contains(t, bytes, "varFloat=3.14")
-	assert.Contains(t, bytes, "test info")
+	assert.NotContains(t, bytes, "test info")
 	assert.Contains(t, bytes, "test warning")
-	assert.NotContains(t, bytes, "test error")
+	assert.Contains(t, bytes, "test error")
 	assert.NotContains(t, bytes, "test debug")
 }
 
@@ -327,8 +476,8 @@ func TestTransactionError(t *testing.T) {
 	assert.Contains(t, bytes, "varInt=0")
 	assert.Contains(t, bytes, "varStr=string")
 	assert.Contains(t, bytes, "varFloat=3.14")
-	assert.Contains(t, bytes, "test info")
-	assert.Contains(t, bytes, "test warning")
+	assert.NotContains(t, bytes, "test info")
+	assert.NotContains(t, bytes, "test warning")
 	assert.Contains(t, bytes, "test error")
 	assert.NotContains(t, bytes, "test debug")
 }
@@ -417,14 +566,15 @@ func TestStartTransactionLogging(t *testing.T) {
 
 	value, found := availableTransactions.Load(testTransactionId)
 
-	castedVal, ok := value.(*TransactionLoggerData)
+	castedVal, ok := value.(*transactionState)
 	if !ok {
-		t.Fatalf("fatal: transaction log could not be casted to TransactionLoggerData type for transaction %s %v", testTransactionId, err)
+		t.Fatalf("fatal: transaction log could not be casted to transactionState type for transaction %s %v", testTransactionId, err)
 	}
 
 	assert.True(t, found)
-	assert.Equal(t, LevelInfo, castedVal.LoggerLevel)
-	assert.Empty(t, castedVal.TransactionLogs)
+	assert.Same(t, log.state, castedVal)
+	assert.Equal(t, LevelInfo, castedVal.data.LoggerLevel)
+	assert.Empty(t, castedVal.data.TransactionLogs)
 }
 
 func TestStopTransactionLoggingWithLoggingOff(t *testing.T) {
@@ -501,12 +651,376 @@ func TestAddLogToTransaction(t *testing.T) {
 
 	value, found := availableTransactions.Load(testTransactionId)
 
-	castedVal, ok := value.(*TransactionLoggerData)
+	castedVal, ok := value.(*transactionState)
 	if !ok {
-		t.Fatalf("fatal: transaction log could not be casted to TransactionLoggerData type for transaction %s %v", testTransactionId, err)
+		t.Fatalf("fatal: transaction log could not be casted to transactionState type for transaction %s %v", testTransactionId, err)
 	}
 
 	assert.True(t, found)
-	assert.Equal(t, LevelInfo, castedVal.LoggerLevel)
-	assert.Contains(t, castedVal.TransactionLogs, &insertedLog)
+	assert.Same(t, log.state, castedVal)
+	assert.Equal(t, LevelInfo, castedVal.data.LoggerLevel)
+	assert.Contains(t, castedVal.data.TransactionLogs, &insertedLog)
+}
+
+func TestTransactionWith(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var transactions []*TransactionLoggerData
+	log, err := NewTransactionLog(testTransactionId, WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	requestLog := log.With(map[string]any{
+		"requestId": "1",
+	})
+	userLog := requestLog.With(map[string]any{
+		"userId": 2,
+	})
+
+	err = userLog.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	requestLog.Info("test request", nil)
+	userLog.Info("test user", map[string]any{
+		"requestId": "override",
+	})
+	err = requestLog.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Len(t, transactions, 1)
+	transactionLogs := transactions[0].TransactionLogs
+	assert.Len(t, transactionLogs, 2)
+	assert.Equal(t, MetaData{"requestId": "1"}, transactionLogs[0].MetaData)
+	assert.Equal(t, MetaData{"requestId": "override", "userId": 2}, transactionLogs[1].MetaData)
+	assert.False(t, log.startTimestamp.IsZero())
+}
+
+func TestTransactionInfoFields(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	log, err := NewTransactionLog(testTransactionId)
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	bytes, err := itesting.CaptureOutput(func() error {
+		err := log.StartTransactionLogging()
+		if err != nil {
+			return err
+		}
+		log.InfoFields("test info", Field{"varStr", "string"}, Field{"varInt", 0})
+		log.DebugFields("test debug", Field{"varInt", 0})
+		return log.StopTransactionLogging()
+	})
+	if err != nil {
+		t.Fatalf("error: could not capture stdout output %v", err)
+	}
+
+	assert.Contains(t, bytes, "test info [varStr=string] [varInt=0]\n")
+	assert.NotContains(t, bytes, "test debug")
+}
+
+func TestStopTransactionLoggingReturnsImmediately(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	log, err := NewTransactionLog(testTransactionId)
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+
+	start := time.Now()
+	bytes, err := itesting.CaptureOutput(func() error {
+		log.Info("test", nil)
+		return log.StopTransactionLogging()
+	})
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Less(t, time.Since(start), defaultTransactionStopTimeout)
+	assert.NotContains(t, bytes, "couple of seconds")
+}
+
+func TestStopTransactionLoggingWaitsForInFlightLogs(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var transactions []*TransactionLoggerData
+	log, err := NewTransactionLog(testTransactionId, WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	log.Info("test", nil)
+
+	// a log that is still being added to the transaction
+	log.state.inFlight.add()
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- log.StopTransactionLogging()
+	}()
+	assert.Eventually(t, func() bool {
+		log.state.inFlight.mutex.Lock()
+		defer log.state.inFlight.mutex.Unlock()
+		return log.state.inFlight.stopping
+	}, time.Second, time.Millisecond)
+
+	err = log.addLogToTransaction(&LoggerData{LoggerLevel: LevelInfo, Message: "test after stop"})
+	assert.Equal(t, fmt.Sprintf("error: the provided transaction was not started or was recently ended %s", testTransactionId), err.Error())
+	select {
+	case <-stopped:
+		t.Fatalf("fatal: the transaction was stopped before the in-flight log was added")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	log.state.inFlight.done()
+	err = <-stopped
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Len(t, transactions, 1)
+	assert.Equal(t, []string{"test"}, transactionLogMessages(transactions[0]))
+}
+
+func TestStopTransactionLoggingWithStopTimeout(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	log, err := NewTransactionLog(testTransactionId, WithTransactionStopTimeout(10*time.Millisecond), WithTransactionLogOutputWriter(func(_ string, _ time.Time, _ time.Time, _ *TransactionLoggerData) error {
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+
+	// a log that is never done being added to the transaction
+	log.state.inFlight.add()
+	t.Cleanup(log.state.inFlight.done)
+
+	start := time.Now()
+	err = log.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Less(t, time.Since(start), defaultTransactionStopTimeout)
+	_, found := availableTransactions.Load(testTransactionId)
+	assert.False(t, found)
+}
+
+func TestStartSubTransaction(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var transactionLoggerData *TransactionLoggerData
+	var endTimestamp time.Time
+	log, err := NewTransactionLog(testTransactionId, WithTransactionLogOutputWriter(func(_ string, _ time.Time, end time.Time, data *TransactionLoggerData) error {
+		transactionLoggerData = data
+		endTimestamp = end
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	log.Info("test root", nil)
+
+	paymentLog, err := log.With(map[string]any{"requestId": "1"}).StartSubTransaction("payment")
+	if err != nil {
+		t.Fatalf("fatal: sub-transaction could not be started for transaction %s %v", testTransactionId, err)
+	}
+	chargeLog, err := paymentLog.StartSubTransaction("charge card")
+	if err != nil {
+		t.Fatalf("fatal: sub-transaction could not be started for transaction %s %v", testTransactionId, err)
+	}
+	chargeLog.Info("test charge", nil)
+	err = chargeLog.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: sub-transaction could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	err = chargeLog.addLogToTransaction(&LoggerData{LoggerLevel: LevelInfo, Message: "test after stop"})
+	assert.Equal(t, "error: the provided sub-transaction was recently ended charge card", err.Error())
+	assert.Equal(t, "error: the provided sub-transaction is started using StartSubTransaction payment", paymentLog.StartTransactionLogging().Error())
+
+	// the payment sub-transaction is not stopped, it ends with the root transaction
+	err = log.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Len(t, transactionLoggerData.TransactionLogs, 1)
+	assert.Equal(t, "test root", transactionLoggerData.TransactionLogs[0].Message)
+	assert.Len(t, transactionLoggerData.SubTransactions, 1)
+
+	payment := transactionLoggerData.SubTransactions[0]
+	assert.Equal(t, "payment", payment.TransactionID)
+	assert.Equal(t, endTimestamp, payment.EndTimestamp)
+	assert.Empty(t, payment.TransactionLogs)
+	assert.Len(t, payment.SubTransactions, 1)
+
+	charge := payment.SubTransactions[0]
+	assert.Equal(t, "charge card", charge.TransactionID)
+	assert.False(t, charge.StartTimestamp.Before(payment.StartTimestamp))
+	assert.True(t, charge.EndTimestamp.Before(endTimestamp))
+	assert.Len(t, charge.TransactionLogs, 1)
+	assert.Equal(t, "test charge", charge.TransactionLogs[0].Message)
+	assert.Equal(t, "1", charge.TransactionLogs[0].MetaData["requestId"])
+
+	err = chargeLog.StopTransactionLogging()
+	assert.Equal(t, fmt.Sprintf("error: the provided transaction was not started or was recently ended %s", testTransactionId), err.Error())
+}
+
+func TestStartSubTransactionNotStarted(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	log, err := NewTransactionLog(testTransactionId)
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	subLog, err := log.StartSubTransaction("payment")
+
+	assert.Nil(t, subLog)
+	assert.Equal(t, fmt.Sprintf("error: the provided transaction was not started or was recently ended %s", testTransactionId), err.Error())
+}
+
+func TestStartSubTransactionWithLoggingOff(t *testing.T) {
+	log := FromContext(context.Background())
+
+	subLog, err := log.StartSubTransaction("payment")
+	if err != nil {
+		t.Fatalf("fatal: sub-transaction could not be started %v", err)
+	}
+
+	assert.Equal(t, LevelOff, subLog.loggerLevel)
+	subLog.Info("test", nil)
+	assert.Nil(t, subLog.StopTransactionLogging())
+}
+
+func TestAddLogToTransactionConcurrently(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	transactionLoggerData := map[string]*TransactionLoggerData{}
+	var outputMutex sync.Mutex
+	outputWriter := WithTransactionLogOutputWriter(func(transactionId string, _ time.Time, _ time.Time, data *TransactionLoggerData) error {
+		outputMutex.Lock()
+		defer outputMutex.Unlock()
+		transactionLoggerData[transactionId] = data
+		return nil
+	})
+
+	transactionIds := []string{"first", "second", "third"}
+	var wg sync.WaitGroup
+	for _, transactionId := range transactionIds {
+		log, err := NewTransactionLog(transactionId, outputWriter)
+		if err != nil {
+			t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", transactionId, err)
+		}
+		err = log.StartTransactionLogging()
+		if err != nil {
+			t.Fatalf("fatal: transaction log could not be started for transaction %s %v", transactionId, err)
+		}
+
+		var logsWg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			logsWg.Add(1)
+			go func() {
+				defer logsWg.Done()
+				log.Info("test", nil)
+			}()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logsWg.Wait()
+			err := log.StopTransactionLogging()
+			if err != nil {
+				t.Errorf("error: transaction log could not be stopped for transaction %s %v", transactionId, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, transactionId := range transactionIds {
+		assert.Len(t, transactionLoggerData[transactionId].TransactionLogs, 100)
+	}
+	assert.Empty(t, OpenTransactions())
+}
+
+// BenchmarkParallelTransactions runs a transaction per goroutine, showing how the transactions scale when running in parallel.
+// Use -cpu=1,2,4,8 to compare.
+func BenchmarkParallelTransactions(b *testing.B) {
+	transactionLoggerOnce = sync.Once{}
+	outputWriter := WithTransactionLogOutputWriter(func(string, time.Time, time.Time, *TransactionLoggerData) error {
+		return nil
+	})
+
+	var transactionCount atomic.Int64
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		// FailNow must not be called from the goroutines of RunParallel, the failures are reported using Errorf
+		for pb.Next() {
+			transactionId := fmt.Sprintf("benchmark-%d", transactionCount.Add(1))
+			log, err := NewTransactionLog(transactionId, outputWriter)
+			if err != nil {
+				b.Errorf("error: transaction log could not be initialized for transaction %s %v", transactionId, err)
+				return
+			}
+			err = log.StartTransactionLogging()
+			if err != nil {
+				b.Errorf("error: transaction log could not be started for transaction %s %v", transactionId, err)
+				return
+			}
+			for i := 0; i < 10; i++ {
+				log.Info("test", nil)
+			}
+			err = log.StopTransactionLogging()
+			if err != nil {
+				b.Errorf("error: transaction log could not be stopped for transaction %s %v", transactionId, err)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkParallelLogsInTransaction adds logs to a single transaction from every goroutine, showing the contention on a transaction.
+func BenchmarkParallelLogsInTransaction(b *testing.B) {
+	transactionLoggerOnce = sync.Once{}
+	log, err := NewTransactionLog(testTransactionId, WithTransactionLogOutputWriter(func(string, time.Time, time.Time, *TransactionLoggerData) error {
+		return nil
+	}))
+	if err != nil {
+		b.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+	err = log.StartTransactionLogging()
+	if err != nil {
+		b.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	b.Cleanup(func() {
+		err := log.StopTransactionLogging()
+		if err != nil {
+			b.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+		}
+	})
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Info("test", nil)
+		}
+	})
 }
@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// A TransactionLogger is the transaction logger, named so that the functions run by RunInTransaction can be declared outside of the package.
+type TransactionLogger = transactionLogging
+
+// WithTransactionRepanic is a pre-defined "driver" that specifies if RunInTransaction panics again
+// with the recovered value, once the transaction was written.
+func WithTransactionRepanic(repanic bool) func(*transactionLogging) {
+	return func(l *transactionLogging) {
+		l.repanic = repanic
+	}
+}
+
+// RunInTransaction creates and starts a transaction, using NewTransactionLog, runs the function with the transaction logger
+// and always stops the transaction, so that it is written even if the function panics.
+//
+// The error returned by the function is recorded as the outcome of the transaction, see StopTransactionLoggingWithError, and returned.
+// A panic is recovered and recorded as an Error log holding the panic value and the stack trace, whatever the transaction log level,
+// the transaction ending with TransactionStatusError. The panic is then returned as an error,
+// or raised again if the transaction logger was configured using WithTransactionRepanic.
+//
+// The function must not stop the transaction itself.
+func RunInTransaction(transactionId string, fn func(tx *TransactionLogger) error, options ...func(*transactionLogging)) (err error) {
+	tx, err := NewTransactionLog(transactionId, options...)
+	if err != nil {
+		return err
+	}
+	err = tx.StartTransactionLogging()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		if tx.loggerLevel != LevelOff {
+			// the panic is recorded whatever the level, so that it is never lost
+			metaData, metaDataOrder := fieldsToMetaData([]Field{{Key: "panic", Value: r}, {Key: "stack", Value: string(debug.Stack())}})
+			addErr := tx.addLogToTransaction(&LoggerData{LoggerLevel: LevelError, Timestamp: time.Now(), Message: "panic recovered", MetaData: metaData, MetaDataOrder: metaDataOrder})
+			if addErr != nil {
+				fmt.Println(addErr)
+			}
+		}
+		stopErr := tx.StopTransactionLoggingWithStatus(TransactionStatusError, fmt.Sprintf("panic: %v", r))
+
+		if tx.repanic {
+			panic(r)
+		}
+		err = errors.Join(fmt.Errorf("error: the transaction panicked %s %v", transactionId, r), stopErr)
+	}()
+
+	fnErr := fn(tx)
+	stopErr := tx.StopTransactionLoggingWithError(fnErr)
+	if stopErr != nil {
+		return errors.Join(fnErr, stopErr)
+	}
+	return fnErr
+}
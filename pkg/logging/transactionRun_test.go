@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTransactionRepanic(t *testing.T) {
+	l := transactionLogging{}
+	WithTransactionRepanic(true)(&l)
+	assert.True(t, l.repanic)
+}
+
+func TestRunInTransaction(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var transactions []*TransactionLoggerData
+
+	err := RunInTransaction(testTransactionId, func(tx *TransactionLogger) error {
+		tx.Info("test", nil)
+		return nil
+	}, WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+
+	assert.Nil(t, err)
+	assert.Len(t, transactions, 1)
+	transactionLoggerData := transactions[0]
+	assert.Equal(t, TransactionStatusOk, transactionLoggerData.Status)
+	assert.Len(t, transactionLoggerData.TransactionLogs, 1)
+	assert.Empty(t, OpenTransactions())
+}
+
+func TestRunInTransactionWithError(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var transactions []*TransactionLoggerData
+	errTest := errors.New("test error")
+
+	err := RunInTransaction(testTransactionId, func(tx *TransactionLogger) error {
+		return errTest
+	}, WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+
+	assert.Same(t, errTest, err)
+	assert.Len(t, transactions, 1)
+	transactionLoggerData := transactions[0]
+	assert.Equal(t, TransactionStatusError, transactionLoggerData.Status)
+	assert.Equal(t, "test error", transactionLoggerData.StatusMessage)
+}
+
+func TestRunInTransactionWithPanic(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var transactions []*TransactionLoggerData
+
+	err := RunInTransaction(testTransactionId, func(tx *TransactionLogger) error {
+		tx.Info("test", nil)
+		panic("test panic")
+	}, WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+
+	assert.Equal(t, fmt.Sprintf("error: the transaction panicked %s test panic", testTransactionId), err.Error())
+	assert.Len(t, transactions, 1)
+	transactionLoggerData := transactions[0]
+	assert.Equal(t, TransactionStatusError, transactionLoggerData.Status)
+	assert.Equal(t, "panic: test panic", transactionLoggerData.StatusMessage)
+	assert.Len(t, transactionLoggerData.TransactionLogs, 2)
+
+	panicLog := transactionLoggerData.TransactionLogs[1]
+	assert.Equal(t, LevelError, panicLog.LoggerLevel)
+	assert.Equal(t, "panic recovered", panicLog.Message)
+	assert.Equal(t, "test panic", panicLog.MetaData["panic"])
+	assert.Contains(t, panicLog.MetaData["stack"], "TestRunInTransactionWithPanic")
+	assert.Equal(t, []string{"panic", "stack"}, panicLog.MetaDataKeys())
+	assert.Empty(t, OpenTransactions())
+}
+
+func TestRunInTransactionWithRepanic(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	var transactions []*TransactionLoggerData
+
+	assert.PanicsWithValue(t, "test panic", func() {
+		_ = RunInTransaction(testTransactionId, func(tx *TransactionLogger) error {
+			panic("test panic")
+		}, WithTransactionRepanic(true), WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+	})
+
+	assert.Len(t, transactions, 1)
+	transactionLoggerData := transactions[0]
+	assert.Equal(t, TransactionStatusError, transactionLoggerData.Status)
+	assert.Len(t, transactionLoggerData.TransactionLogs, 1)
+}
+
+func TestRunInTransactionAlreadyStarted(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	log, err := NewTransactionLog(testTransactionId)
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	t.Cleanup(func() {
+		err := log.StopTransactionLogging()
+		if err != nil {
+			t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+		}
+	})
+
+	called := false
+	err = RunInTransaction(testTransactionId, func(tx *TransactionLogger) error {
+		called = true
+		return nil
+	})
+
+	assert.False(t, called)
+	assert.Equal(t, fmt.Sprintf("error: the provided transaction was already started %s", testTransactionId), err.Error())
+}
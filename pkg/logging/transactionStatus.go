@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Transaction statuses, recording the outcome of a transaction
+const (
+	TransactionStatusOk        TransactionStatus = "ok"
+	TransactionStatusError     TransactionStatus = "error"
+	TransactionStatusCancelled TransactionStatus = "cancelled"
+	TransactionStatusTimedOut  TransactionStatus = "timedOut" // set when the transaction exceeded its maximum lifetime
+)
+
+// A TransactionStatus is the outcome of a transaction, written with the transaction.
+// A transaction stopped without a status is written with TransactionStatusOk.
+type TransactionStatus string
+
+// SetStatus records the outcome of the transaction, or of the sub-transaction for a logger returned by StartSubTransaction,
+// with an optional message (e.g. the error that failed the transaction). A later call overrides the status.
+//
+// If the transaction was not started or was already stopped, an error will be returned.
+//
+// SetStatus is safe to call concurrently with other operations and will
+// block until the other operations on the same transaction finish.
+func (l *transactionLogging) SetStatus(status TransactionStatus, message string) error {
+	if l.loggerLevel == LevelOff {
+		return nil
+	}
+
+	state := l.currentState()
+	if state == nil {
+		return fmt.Errorf("error: the provided transaction was not started or was recently ended %s", l.transactionId)
+	}
+	return state.update(l.subTransaction, func(data *TransactionLoggerData) {
+		data.Status = status
+		data.StatusMessage = message
+	})
+}
+
+// StopTransactionLoggingWithStatus records the outcome of the transaction, using SetStatus, then stops it using StopTransactionLogging.
+func (l *transactionLogging) StopTransactionLoggingWithStatus(status TransactionStatus, message string) error {
+	err := l.SetStatus(status, message)
+	if err != nil {
+		return err
+	}
+	return l.StopTransactionLogging()
+}
+
+// StopTransactionLoggingWithError stops the transaction with an outcome respective to the given error:
+// TransactionStatusOk if the error is nil, TransactionStatusCancelled if it is a context cancellation
+// and TransactionStatusError otherwise, the error being recorded as the status message.
+func (l *transactionLogging) StopTransactionLoggingWithError(err error) error {
+	switch {
+	case err == nil:
+		return l.StopTransactionLoggingWithStatus(TransactionStatusOk, "")
+	case errors.Is(err, context.Canceled):
+		return l.StopTransactionLoggingWithStatus(TransactionStatusCancelled, err.Error())
+	default:
+		return l.StopTransactionLoggingWithStatus(TransactionStatusError, err.Error())
+	}
+}
@@ -0,0 +1,154 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startRecordedTransaction starts a transaction whose written data is appended to the returned slice.
+func startRecordedTransaction(t *testing.T) (*transactionLogging, *[]*TransactionLoggerData) {
+	transactionLoggerOnce = sync.Once{}
+	var transactions []*TransactionLoggerData
+	log, err := NewTransactionLog(testTransactionId, WithTransactionLogOutputWriter(recordingChunksTransactionLogOutputWrite(&transactions)))
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.StartTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be started for transaction %s %v", testTransactionId, err)
+	}
+	return log, &transactions
+}
+
+func TestStopTransactionLoggingWithoutStatus(t *testing.T) {
+	log, transactions := startRecordedTransaction(t)
+
+	err := log.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Len(t, *transactions, 1)
+	transactionLoggerData := (*transactions)[0]
+	assert.Equal(t, TransactionStatusOk, transactionLoggerData.Status)
+	assert.Empty(t, transactionLoggerData.StatusMessage)
+}
+
+func TestSetStatus(t *testing.T) {
+	log, transactions := startRecordedTransaction(t)
+
+	err := log.SetStatus(TransactionStatusError, "test error")
+	if err != nil {
+		t.Fatalf("fatal: could not set the status of transaction %s %v", testTransactionId, err)
+	}
+	log.Info("test", nil)
+	err = log.StopTransactionLogging()
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Len(t, *transactions, 1)
+	transactionLoggerData := (*transactions)[0]
+	assert.Equal(t, TransactionStatusError, transactionLoggerData.Status)
+	assert.Equal(t, "test error", transactionLoggerData.StatusMessage)
+	assert.Len(t, transactionLoggerData.TransactionLogs, 1)
+}
+
+func TestSetStatusNotStarted(t *testing.T) {
+	transactionLoggerOnce = sync.Once{}
+	log, err := NewTransactionLog(testTransactionId)
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be initialized for transaction %s %v", testTransactionId, err)
+	}
+
+	err = log.SetStatus(TransactionStatusError, "test error")
+
+	assert.Equal(t, fmt.Sprintf("error: the provided transaction was not started or was recently ended %s", testTransactionId), err.Error())
+	assert.Nil(t, FromContext(context.Background()).SetStatus(TransactionStatusError, "test error"))
+}
+
+func TestStopTransactionLoggingWithErrorTableDriven(t *testing.T) {
+	type Expected struct {
+		Status        TransactionStatus
+		StatusMessage string
+	}
+	type TestCase struct {
+		TestName string
+		Data     error
+		Expected Expected
+	}
+
+	testCases := []TestCase{
+		{
+			TestName: "No error",
+			Data:     nil,
+			Expected: Expected{Status: TransactionStatusOk},
+		},
+		{
+			TestName: "Error",
+			Data:     errors.New("test error"),
+			Expected: Expected{Status: TransactionStatusError, StatusMessage: "test error"},
+		},
+		{
+			TestName: "Wrapped context cancellation",
+			Data:     fmt.Errorf("test request %w", context.Canceled),
+			Expected: Expected{Status: TransactionStatusCancelled, StatusMessage: "test request context canceled"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.TestName, func(t *testing.T) {
+			log, transactions := startRecordedTransaction(t)
+
+			err := log.StopTransactionLoggingWithError(test.Data)
+			if err != nil {
+				t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+			}
+
+			assert.Len(t, *transactions, 1)
+			transactionLoggerData := (*transactions)[0]
+			assert.Equal(t, test.Expected.Status, transactionLoggerData.Status)
+			assert.Equal(t, test.Expected.StatusMessage, transactionLoggerData.StatusMessage)
+		})
+	}
+}
+
+func TestSubTransactionStatus(t *testing.T) {
+	log, transactions := startRecordedTransaction(t)
+
+	stoppedLog, err := log.StartSubTransaction("stopped")
+	if err != nil {
+		t.Fatalf("fatal: sub-transaction could not be started for transaction %s %v", testTransactionId, err)
+	}
+	err = stoppedLog.StopTransactionLoggingWithStatus(TransactionStatusError, "test error")
+	if err != nil {
+		t.Fatalf("fatal: sub-transaction could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+	assert.Equal(t, "error: the provided sub-transaction was recently ended stopped", stoppedLog.SetStatus(TransactionStatusOk, "").Error())
+
+	_, err = log.StartSubTransaction("open")
+	if err != nil {
+		t.Fatalf("fatal: sub-transaction could not be started for transaction %s %v", testTransactionId, err)
+	}
+
+	// the open sub-transaction takes the status of the transaction
+	err = log.StopTransactionLoggingWithStatus(TransactionStatusCancelled, "")
+	if err != nil {
+		t.Fatalf("fatal: transaction log could not be stopped for transaction %s %v", testTransactionId, err)
+	}
+
+	assert.Len(t, *transactions, 1)
+	transactionLoggerData := (*transactions)[0]
+	subTransactions := transactionLoggerData.SubTransactions
+	assert.Len(t, subTransactions, 2)
+	assert.Equal(t, TransactionStatusError, subTransactions[0].Status)
+	assert.Equal(t, "test error", subTransactions[0].StatusMessage)
+	assert.Equal(t, TransactionStatusCancelled, subTransactions[1].Status)
+	assert.Equal(t, TransactionStatusCancelled, transactionLoggerData.Status)
+}